@@ -1,18 +1,124 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/konkasidiaris/gitvault/internal/logging"
 	"github.com/konkasidiaris/gitvault/internal/sync"
 )
 
+const defaultPruneGracePeriod = 30 * 24 * time.Hour
+
+// Exit codes let cron wrappers and health checks tell a broken run from one
+// where only some repositories could not be synced.
+const (
+	exitError              = 1
+	exitRepositoriesFailed = 2
+)
+
 func main() {
 	logging.InitializeLogger()
 
-	if err := sync.Run(); err != nil {
-		slog.Error("sync failed", "error", err)
-		os.Exit(1)
+	command, args := "sync", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := execute(ctx, command, args)
+	stop()
+
+	if err != nil {
+		slog.Error(command+" failed", "error", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+func exitCode(err error) int {
+	if errors.Is(err, sync.ErrRepositoriesFailed) && !errors.Is(err, context.Canceled) {
+		return exitRepositoriesFailed
+	}
+	return exitError
+}
+
+func execute(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "sync":
+		return runSync(ctx, args)
+	case "prune":
+		return runPrune(args)
+	case "history":
+		return runHistory(args)
+	default:
+		return fmt.Errorf("unknown command %q, expected sync, prune or history", command)
+	}
+}
+
+func runSync(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	maxParallel := flags.Int("max-parallel", 0, "number of repositories mirrored concurrently (overrides max_parallel from the configuration)")
+	force := flags.Bool("force", false, "fetch every repository, even those with nothing pushed since the last fetch")
+	dryRun := flags.Bool("dry-run", false, "print what would be done for every repository without running git or writing state")
+	jsonOutput := flags.Bool("json", false, "print the dry-run plan as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := sync.Options{MaxParallel: *maxParallel, Force: *force}
+	if *dryRun {
+		return planSync(ctx, options, *jsonOutput)
 	}
+	if *jsonOutput {
+		return errors.New("--json requires --dry-run")
+	}
+
+	result, err := sync.Run(ctx, options)
+	if result != nil {
+		if err := result.WriteSummary(os.Stdout); err != nil {
+			slog.Warn("failed to print summary", "error", err)
+		}
+	}
+	return err
+}
+
+func planSync(ctx context.Context, options sync.Options, jsonOutput bool) error {
+	plan, err := sync.Plan(ctx, options)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return plan.WriteJSON(os.Stdout)
+	}
+	return plan.WriteSummary(os.Stdout)
+}
+
+func runPrune(args []string) error {
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	gracePeriod := flags.Duration("grace-period", defaultPruneGracePeriod, "how long a repository stays soft-deleted before its mirror is removed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return sync.Prune(*gracePeriod)
+}
+
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return errors.New("usage: gitvault history <repository> [ref]")
+	}
+
+	return sync.History(os.Stdout, flags.Arg(0), flags.Arg(1))
 }
@@ -204,7 +204,7 @@ func TestWireMock(t *testing.T) {
 // 	// This validates the full pipeline: config → API call → clone attempt.
 // 	t.Logf("gitvault output:\n%s", output)
 
-// 	// The program should still exit (it continues on clone errors).
+// 	// The program should still finish (it continues on clone errors and exits 2).
 // 	// We check that the GitHub API was called and repos were discovered.
 // 	assert.Contains(t, output, "fetched 2 repositories from GitHub")
 // 	assert.Contains(t, output, "testuser/repo-alpha")
@@ -212,8 +212,8 @@ func TestWireMock(t *testing.T) {
 
 // 	// Even though clone fails, we verify it didn't crash with a config or API error.
 // 	if err != nil {
-// 		// gitvault logs clone errors but still exits 0 — if it exits non-zero,
-// 		// it means the config or API layer failed, which would be a real bug.
+// 		// gitvault exits 2 when only clones failed — any other failure means
+// 		// the config or API layer failed, which would be a real bug.
 // 		assert.Contains(t, output, "clone", "if exit non-zero, expected clone-related error, not config/API")
 // 	}
 // }
@@ -0,0 +1,30 @@
+// Package bitbucket lists repositories from Bitbucket Cloud and from
+// Bitbucket Server and Data Center, whose APIs share little beyond the
+// shape of their clone links.
+package bitbucket
+
+import "net/url"
+
+// Link is a named link of a repository, such as one of its clone URLs.
+type Link struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+// cloneLink returns the clone URL with the given name. Bitbucket puts the
+// requesting user in HTTP clone URLs; it is dropped so the URL matches
+// whoever the mirror later authenticates as.
+func cloneLink(links []Link, name string) string {
+	for _, link := range links {
+		if link.Name != name {
+			continue
+		}
+		parsed, err := url.Parse(link.Href)
+		if err != nil || parsed.User == nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return link.Href
+		}
+		parsed.User = nil
+		return parsed.String()
+	}
+	return ""
+}
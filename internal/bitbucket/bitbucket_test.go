@@ -0,0 +1,28 @@
+package bitbucket
+
+import "testing"
+
+func TestCloneLink(t *testing.T) {
+	links := []Link{
+		{Name: "https", Href: "https://alice@bitbucket.org/acme/api.git"},
+		{Name: "ssh", Href: "git@bitbucket.org:acme/api.git"},
+	}
+
+	if got := cloneLink(links, "https"); got != "https://bitbucket.org/acme/api.git" {
+		t.Fatalf("https = %s, want the URL without the user", got)
+	}
+	if got := cloneLink(links, "ssh"); got != "git@bitbucket.org:acme/api.git" {
+		t.Fatalf("ssh = %s, want git@bitbucket.org:acme/api.git", got)
+	}
+	if got := cloneLink(links, "http"); got != "" {
+		t.Fatalf("http = %s, want none", got)
+	}
+}
+
+func TestCloneLink_KeepsSSHUser(t *testing.T) {
+	links := []Link{{Name: "ssh", Href: "ssh://git@bitbucket.example.com:7999/plat/api.git"}}
+
+	if got := cloneLink(links, "ssh"); got != "ssh://git@bitbucket.example.com:7999/plat/api.git" {
+		t.Fatalf("ssh = %s, want the URL unchanged", got)
+	}
+}
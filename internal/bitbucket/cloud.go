@@ -0,0 +1,140 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+// pageLength is the page size asked of Bitbucket Cloud, its maximum.
+const pageLength = 100
+
+// CloudRepository is a repository as listed by the Bitbucket Cloud 2.0 API.
+// Size is in bytes.
+type CloudRepository struct {
+	UUID      string    `json:"uuid"`
+	FullName  string    `json:"full_name"`
+	IsPrivate bool      `json:"is_private"`
+	UpdatedOn time.Time `json:"updated_on"`
+	Size      int64     `json:"size"`
+	Parent    *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	Links struct {
+		Clone []Link `json:"clone"`
+	} `json:"links"`
+}
+
+// repository converts a listed repository to the model sync consumes.
+// Bitbucket Cloud identifies repositories by UUID, which is hashed into the
+// numeric ID sync tracks renames by. It has no push timestamp; updated_on
+// moves on every push and stands in for it.
+func (r CloudRepository) repository() forge.Repository {
+	id := fnv.New64a()
+	id.Write([]byte(r.UUID))
+
+	return forge.Repository{
+		ID:        int64(id.Sum64() >> 1),
+		FullName:  r.FullName,
+		SSHURL:    cloneLink(r.Links.Clone, "ssh"),
+		CloneURL:  cloneLink(r.Links.Clone, "https"),
+		PushedAt:  r.UpdatedOn,
+		UpdatedAt: r.UpdatedOn,
+		Fork:      r.Parent != nil,
+		Private:   r.IsPrivate,
+		Size:      r.Size / 1024,
+	}
+}
+
+// cloudPage is one page of a Bitbucket Cloud listing. Next is the URL of the
+// following page, empty on the last one.
+type cloudPage struct {
+	Values []CloudRepository `json:"values"`
+	Next   string            `json:"next"`
+}
+
+var _ forge.Provider = (*CloudClient)(nil)
+
+// CloudClient lists repositories from Bitbucket Cloud. The token is an app
+// password or API token of username.
+type CloudClient struct {
+	baseURL    string
+	username   string
+	token      string
+	workspaces []string
+	http       *http.Client
+}
+
+func NewCloudClient(source config.SourceConfig) *CloudClient {
+	return &CloudClient{
+		baseURL:    source.BaseURL + "/2.0",
+		username:   source.Username,
+		token:      source.Token,
+		workspaces: source.Workspaces,
+		http:       &http.Client{},
+	}
+}
+
+// ListRepositories lists the repositories of the configured workspaces, or
+// every repository the user is a member of when none is configured. A
+// repository listed more than once is returned once.
+func (c *CloudClient) ListRepositories(ctx context.Context) ([]forge.Repository, error) {
+	if len(c.workspaces) == 0 {
+		return c.GetMemberRepos(ctx)
+	}
+
+	var repositories []forge.Repository
+	for _, workspace := range c.workspaces {
+		repos, err := c.GetWorkspaceRepos(ctx, workspace)
+		if err != nil {
+			return nil, fmt.Errorf("workspace %s: %w", workspace, err)
+		}
+		repositories = append(repositories, repos...)
+	}
+
+	return forge.Deduplicate(repositories), nil
+}
+
+// GetMemberRepos lists every repository the user is a member of.
+func (c *CloudClient) GetMemberRepos(ctx context.Context) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, fmt.Sprintf("%s/repositories?role=member&pagelen=%d", c.baseURL, pageLength))
+}
+
+// GetWorkspaceRepos lists the repositories of a workspace the user can see.
+func (c *CloudClient) GetWorkspaceRepos(ctx context.Context, workspace string) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, fmt.Sprintf("%s/repositories/%s?pagelen=%d", c.baseURL, url.PathEscape(workspace), pageLength))
+}
+
+// getAllPages follows the next URL of every page until the last one.
+func (c *CloudClient) getAllPages(ctx context.Context, url string) ([]forge.Repository, error) {
+	var repos []forge.Repository
+
+	for page := 1; url != ""; page++ {
+		var listed cloudPage
+		if _, err := forge.GetJSON(ctx, c.http, url, c.authenticate, &listed); err != nil {
+			if page > 1 {
+				return nil, &forge.PageError{Page: page, URL: url, Err: err}
+			}
+			return nil, err
+		}
+
+		for _, repo := range listed.Values {
+			repos = append(repos, repo.repository())
+		}
+		url = listed.Next
+	}
+
+	return repos, nil
+}
+
+func (c *CloudClient) authenticate(req *http.Request) {
+	if c.token != "" {
+		req.SetBasicAuth(c.username, c.token)
+	}
+}
@@ -0,0 +1,228 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+func newTestCloudClient(baseURL string, source config.SourceConfig, httpClient *http.Client) *CloudClient {
+	source.BaseURL = baseURL
+	client := NewCloudClient(source)
+	client.http = httpClient
+	return client
+}
+
+func TestCloudListRepositories_WorkspaceRepos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/2.0/repositories/acme" {
+			t.Fatalf("path = %s, want /2.0/repositories/acme", got)
+		}
+		if got := r.URL.Query().Get("pagelen"); got != "100" {
+			t.Fatalf("pagelen = %s, want 100", got)
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "alice" || password != "app-password" {
+			t.Fatalf("basic auth = %s:%s, want alice:app-password", username, password)
+		}
+		w.Write([]byte(`{"values": [{
+			"uuid": "{6f1c2a0e-1f7b-4f5e-9a0d-3c1e2b4a5d6f}",
+			"full_name": "acme/api",
+			"is_private": true,
+			"updated_on": "2026-03-01T10:00:00.123456Z",
+			"size": 204800,
+			"parent": {"full_name": "upstream/api"},
+			"links": {"clone": [
+				{"name": "https", "href": "https://alice@bitbucket.org/acme/api.git"},
+				{"name": "ssh", "href": "git@bitbucket.org:acme/api.git"}
+			]}
+		}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestCloudClient(server.URL, config.SourceConfig{Username: "alice", Token: "app-password", Workspaces: []string{"acme"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if len(repos) != 1 {
+		t.Fatalf("len(repos) = %d, want 1", len(repos))
+	}
+	if repos[0].ID <= 0 {
+		t.Fatalf("ID = %d, want a positive ID derived from the UUID", repos[0].ID)
+	}
+	updatedOn := time.Date(2026, 3, 1, 10, 0, 0, 123456000, time.UTC)
+	expected := forge.Repository{
+		ID:        repos[0].ID,
+		FullName:  "acme/api",
+		SSHURL:    "git@bitbucket.org:acme/api.git",
+		CloneURL:  "https://bitbucket.org/acme/api.git",
+		PushedAt:  updatedOn,
+		UpdatedAt: updatedOn,
+		Fork:      true,
+		Private:   true,
+		Size:      200,
+	}
+	if !reflect.DeepEqual(repos[0], expected) {
+		t.Fatalf("repo = %+v, want %+v", repos[0], expected)
+	}
+}
+
+func TestCloudListRepositories_IDIsStableAcrossRenames(t *testing.T) {
+	renamed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := "acme/api"
+		if renamed {
+			name = "acme/gateway"
+		}
+		fmt.Fprintf(w, `{"values": [{"uuid": "{6f1c2a0e-1f7b-4f5e-9a0d-3c1e2b4a5d6f}", "full_name": %q}]}`, name)
+	}))
+	defer server.Close()
+
+	client := newTestCloudClient(server.URL, config.SourceConfig{Workspaces: []string{"acme"}}, server.Client())
+
+	before, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	renamed = true
+	after, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if before[0].ID != after[0].ID {
+		t.Fatalf("ID changed from %d to %d on rename", before[0].ID, after[0].ID)
+	}
+}
+
+func TestCloudListRepositories_MemberReposWithoutWorkspaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/2.0/repositories" {
+			t.Fatalf("path = %s, want /2.0/repositories", got)
+		}
+		if got := r.URL.Query().Get("role"); got != "member" {
+			t.Fatalf("role = %s, want member", got)
+		}
+		w.Write([]byte(`{"values": [{"uuid": "{1}", "full_name": "alice/dotfiles"}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestCloudClient(server.URL, config.SourceConfig{Username: "alice", Token: "app-password"}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullName != "alice/dotfiles" {
+		t.Fatalf("repos = %+v, want alice/dotfiles", repos)
+	}
+}
+
+func TestCloudListRepositories_FollowsNextURL(t *testing.T) {
+	var server *httptest.Server
+	var requests []string
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`{"values": [{"uuid": "{2}", "full_name": "acme/two"}]}`))
+			return
+		}
+		fmt.Fprintf(w, `{"values": [{"uuid": "{1}", "full_name": "acme/one"}], "next": "%s/2.0/repositories/acme?pagelen=100&page=2"}`, server.URL)
+	}))
+	defer server.Close()
+
+	client := newTestCloudClient(server.URL, config.SourceConfig{Workspaces: []string{"acme"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.FullName)
+	}
+	if want := []string{"acme/one", "acme/two"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	if want := []string{"pagelen=100", "pagelen=100&page=2"}; !reflect.DeepEqual(requests, want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestCloudListRepositories_DeduplicatesAcrossWorkspaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"values": [{"uuid": "{1}", "full_name": "acme/shared"}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestCloudClient(server.URL, config.SourceConfig{Workspaces: []string{"acme", "acme-labs"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("len(repos) = %d, want 1", len(repos))
+	}
+}
+
+func TestCloudListRepositories_LaterPageFailure(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"values": [{"uuid": "{1}", "full_name": "acme/one"}], "next": "%s/2.0/repositories/acme?page=2"}`, server.URL)
+	}))
+	defer server.Close()
+
+	client := newTestCloudClient(server.URL, config.SourceConfig{Workspaces: []string{"acme"}}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	var pageErr *forge.PageError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("expected *forge.PageError, got %T: %v", err, err)
+	}
+	if pageErr.Page != 2 {
+		t.Fatalf("Page = %d, want 2", pageErr.Page)
+	}
+}
+
+func TestCloudListRepositories_WorkspaceNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestCloudClient(server.URL, config.SourceConfig{Workspaces: []string{"missing"}}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	if err == nil || err.Error() != "workspace missing: unexpected status code: 404" {
+		t.Fatalf("err = %v, want workspace missing: unexpected status code: 404", err)
+	}
+}
+
+func TestNewCloudClient(t *testing.T) {
+	client := NewCloudClient(config.SourceConfig{BaseURL: "https://api.bitbucket.org", Username: "alice", Token: "app-password", Workspaces: []string{"acme"}})
+
+	if client.baseURL != "https://api.bitbucket.org/2.0" {
+		t.Fatalf("baseURL = %s, want https://api.bitbucket.org/2.0", client.baseURL)
+	}
+	if client.username != "alice" || client.token != "app-password" || !reflect.DeepEqual(client.workspaces, []string{"acme"}) {
+		t.Fatalf("client not configured from source: %+v", client)
+	}
+}
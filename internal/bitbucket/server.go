@@ -0,0 +1,146 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+// limit is the page size asked of Bitbucket Server, which caps it at its
+// page.max.repositories setting.
+const limit = 100
+
+// ServerRepository is a repository as listed by the Bitbucket Server and Data
+// Center REST API 1.0.
+type ServerRepository struct {
+	ID       int64  `json:"id"`
+	Slug     string `json:"slug"`
+	Public   bool   `json:"public"`
+	Archived bool   `json:"archived"`
+	Project  struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Origin *struct {
+		ID int64 `json:"id"`
+	} `json:"origin"`
+	Links struct {
+		Clone []Link `json:"clone"`
+	} `json:"links"`
+}
+
+// repository converts a listed repository to the model sync consumes. Its
+// full name is the project key and the slug, the path it is cloned from.
+// Bitbucket Server reports neither timestamps nor sizes, so its repositories
+// are fetched on every run.
+func (r ServerRepository) repository() forge.Repository {
+	return forge.Repository{
+		ID:       r.ID,
+		FullName: r.Project.Key + "/" + r.Slug,
+		SSHURL:   cloneLink(r.Links.Clone, "ssh"),
+		CloneURL: cloneLink(r.Links.Clone, "http"),
+		Fork:     r.Origin != nil,
+		Archived: r.Archived,
+		Private:  !r.Public,
+	}
+}
+
+// serverPage is one page of a Bitbucket Server listing. NextPageStart is
+// where the following page starts and only set when IsLastPage is not.
+type serverPage struct {
+	Values        []ServerRepository `json:"values"`
+	IsLastPage    bool               `json:"isLastPage"`
+	NextPageStart int                `json:"nextPageStart"`
+}
+
+var _ forge.Provider = (*ServerClient)(nil)
+
+// ServerClient lists repositories from Bitbucket Server and Data Center. The
+// token is an HTTP access token, or the password of username when one is
+// set.
+type ServerClient struct {
+	baseURL  string
+	username string
+	token    string
+	projects []string
+	http     *http.Client
+}
+
+func NewServerClient(source config.SourceConfig) *ServerClient {
+	return &ServerClient{
+		baseURL:  source.BaseURL + "/rest/api/1.0",
+		username: source.Username,
+		token:    source.Token,
+		projects: source.Projects,
+		http:     &http.Client{},
+	}
+}
+
+// ListRepositories lists the repositories of the configured projects, or
+// every repository the token can read when none is configured. A repository
+// listed more than once is returned once.
+func (c *ServerClient) ListRepositories(ctx context.Context) ([]forge.Repository, error) {
+	if len(c.projects) == 0 {
+		return c.GetAccessibleRepos(ctx)
+	}
+
+	var repositories []forge.Repository
+	for _, project := range c.projects {
+		repos, err := c.GetProjectRepos(ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project, err)
+		}
+		repositories = append(repositories, repos...)
+	}
+
+	return forge.Deduplicate(repositories), nil
+}
+
+// GetAccessibleRepos lists every repository the token can read.
+func (c *ServerClient) GetAccessibleRepos(ctx context.Context) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, c.baseURL+"/repos")
+}
+
+// GetProjectRepos lists the repositories of a project by its key.
+func (c *ServerClient) GetProjectRepos(ctx context.Context, project string) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, fmt.Sprintf("%s/projects/%s/repos", c.baseURL, url.PathEscape(project)))
+}
+
+// getAllPages walks start/limit pagination until the server reports the last
+// page.
+func (c *ServerClient) getAllPages(ctx context.Context, endpoint string) ([]forge.Repository, error) {
+	var repos []forge.Repository
+
+	start := 0
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s?start=%d&limit=%d", endpoint, start, limit)
+		var listed serverPage
+		if _, err := forge.GetJSON(ctx, c.http, url, c.authenticate, &listed); err != nil {
+			if page > 1 {
+				return nil, &forge.PageError{Page: page, URL: url, Err: err}
+			}
+			return nil, err
+		}
+
+		for _, repo := range listed.Values {
+			repos = append(repos, repo.repository())
+		}
+		if listed.IsLastPage || listed.NextPageStart <= start {
+			return repos, nil
+		}
+		start = listed.NextPageStart
+	}
+}
+
+func (c *ServerClient) authenticate(req *http.Request) {
+	switch {
+	case c.token == "":
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.token)
+	default:
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
@@ -0,0 +1,204 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+func newTestServerClient(baseURL string, source config.SourceConfig, httpClient *http.Client) *ServerClient {
+	source.BaseURL = baseURL
+	client := NewServerClient(source)
+	client.http = httpClient
+	return client
+}
+
+func TestServerListRepositories_ProjectRepos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/rest/api/1.0/projects/PLAT/repos" {
+			t.Fatalf("path = %s, want /rest/api/1.0/projects/PLAT/repos", got)
+		}
+		if got := r.URL.Query().Get("limit"); got != "100" {
+			t.Fatalf("limit = %s, want 100", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer http-token" {
+			t.Fatalf("Authorization = %q, want Bearer http-token", got)
+		}
+		w.Write([]byte(`{"isLastPage": true, "values": [{
+			"id": 12,
+			"slug": "api",
+			"public": false,
+			"archived": true,
+			"project": {"key": "PLAT"},
+			"origin": {"id": 3},
+			"links": {"clone": [
+				{"name": "http", "href": "https://alice@bitbucket.example.com/scm/plat/api.git"},
+				{"name": "ssh", "href": "ssh://git@bitbucket.example.com:7999/plat/api.git"}
+			]}
+		}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestServerClient(server.URL, config.SourceConfig{Token: "http-token", Projects: []string{"PLAT"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	expected := []forge.Repository{{
+		ID:       12,
+		FullName: "PLAT/api",
+		SSHURL:   "ssh://git@bitbucket.example.com:7999/plat/api.git",
+		CloneURL: "https://bitbucket.example.com/scm/plat/api.git",
+		Fork:     true,
+		Archived: true,
+		Private:  true,
+	}}
+	if !reflect.DeepEqual(repos, expected) {
+		t.Fatalf("repos = %+v, want %+v", repos, expected)
+	}
+}
+
+func TestServerListRepositories_AccessibleReposWithoutProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/rest/api/1.0/repos" {
+			t.Fatalf("path = %s, want /rest/api/1.0/repos", got)
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "alice" || password != "secret" {
+			t.Fatalf("basic auth = %s:%s, want alice:secret", username, password)
+		}
+		w.Write([]byte(`{"isLastPage": true, "values": [{"id": 1, "slug": "dotfiles", "public": true, "project": {"key": "~ALICE"}}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestServerClient(server.URL, config.SourceConfig{Username: "alice", Token: "secret"}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullName != "~ALICE/dotfiles" || repos[0].Private {
+		t.Fatalf("repos = %+v, want public ~ALICE/dotfiles", repos)
+	}
+}
+
+func TestServerListRepositories_WalksPagesUntilLastPage(t *testing.T) {
+	var starts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("start")
+		starts = append(starts, start)
+		switch start {
+		case "0":
+			w.Write([]byte(`{"isLastPage": false, "nextPageStart": 2, "values": [{"id": 1, "slug": "one", "project": {"key": "PLAT"}}, {"id": 2, "slug": "two", "project": {"key": "PLAT"}}]}`))
+		case "2":
+			w.Write([]byte(`{"isLastPage": true, "values": [{"id": 3, "slug": "three", "project": {"key": "PLAT"}}]}`))
+		default:
+			t.Fatalf("requested start %s past the last page", start)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestServerClient(server.URL, config.SourceConfig{Projects: []string{"PLAT"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 3 {
+		t.Fatalf("len(repos) = %d, want 3", len(repos))
+	}
+	if want := []string{"0", "2"}; !reflect.DeepEqual(starts, want) {
+		t.Fatalf("starts = %v, want %v", starts, want)
+	}
+}
+
+func TestServerListRepositories_StopsWhenNextPageStartDoesNotAdvance(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"isLastPage": false, "values": [{"id": 1, "slug": "one", "project": {"key": "PLAT"}}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestServerClient(server.URL, config.SourceConfig{Projects: []string{"PLAT"}}, server.Client())
+
+	if _, err := client.ListRepositories(context.Background()); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("requests = %d, want 1", requests)
+	}
+}
+
+func TestServerListRepositories_DeduplicatesAcrossProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"isLastPage": true, "values": [{"id": 1, "slug": "shared", "project": {"key": "PLAT"}}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestServerClient(server.URL, config.SourceConfig{Projects: []string{"PLAT", "PLAT"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("len(repos) = %d, want 1", len(repos))
+	}
+}
+
+func TestServerListRepositories_LaterPageFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start") == "0" {
+			w.Write([]byte(`{"isLastPage": false, "nextPageStart": 1, "values": [{"id": 1, "slug": "one", "project": {"key": "PLAT"}}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestServerClient(server.URL, config.SourceConfig{Token: "http-token"}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	var pageErr *forge.PageError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("expected *forge.PageError, got %T: %v", err, err)
+	}
+	if pageErr.Page != 2 {
+		t.Fatalf("Page = %d, want 2", pageErr.Page)
+	}
+}
+
+func TestServerListRepositories_ProjectNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestServerClient(server.URL, config.SourceConfig{Projects: []string{"MISSING"}}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	if err == nil || err.Error() != "project MISSING: unexpected status code: 404" {
+		t.Fatalf("err = %v, want project MISSING: unexpected status code: 404", err)
+	}
+}
+
+func TestNewServerClient(t *testing.T) {
+	client := NewServerClient(config.SourceConfig{BaseURL: "https://bitbucket.example.com", Token: "http-token", Projects: []string{"PLAT"}})
+
+	if client.baseURL != "https://bitbucket.example.com/rest/api/1.0" {
+		t.Fatalf("baseURL = %s, want https://bitbucket.example.com/rest/api/1.0", client.baseURL)
+	}
+	if client.token != "http-token" || !reflect.DeepEqual(client.projects, []string{"PLAT"}) {
+		t.Fatalf("client not configured from source: %+v", client)
+	}
+}
@@ -1,13 +1,48 @@
 package config
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 const version = "v0.0.1"
 const defaultConfigPath = "/secrets/gitvault.json"
+const defaultMaxParallel = 4
+const defaultShutdownTimeout = 5 * time.Second
+const defaultCloneTimeout = time.Hour
+const defaultUpdateTimeout = 30 * time.Minute
+const defaultRetryMaxAttempts = 3
+const defaultRetryInitialBackoff = 10 * time.Second
+const defaultRetryMaxBackoff = 5 * time.Minute
+const defaultReportRetention = 30
+const defaultFullRefreshInterval = 24 * time.Hour
+
+// sourceType describes a kind of source: the address it talks to unless
+// base_url is set, empty for self-hosted forges, and the fields that select
+// its repositories. Static sources talk to no API at all.
+type sourceType struct {
+	baseURL   string
+	selectors []string
+	static    bool
+}
+
+var sourceTypes = map[string]sourceType{
+	SourceTypeGitLab:          {baseURL: "https://gitlab.com", selectors: []string{"users", "groups"}},
+	SourceTypeGitea:           {selectors: []string{"organizations"}},
+	SourceTypeBitbucket:       {baseURL: "https://api.bitbucket.org", selectors: []string{"workspaces"}},
+	SourceTypeBitbucketServer: {selectors: []string{"projects"}},
+	SourceTypeStatic:          {static: true},
+}
 
 type ConfigLoader interface {
 	Load(filepath string) (*GitVaultFileConfig, error)
@@ -22,9 +57,102 @@ func (f *FileConfigLoader) Load(filepath string) (*GitVaultFileConfig, error) {
 var configLoader ConfigLoader = &FileConfigLoader{}
 
 type Config struct {
-	Version        string
-	GitHubToken    string
-	GitHubUsername string
+	Version         string
+	GitHubToken     string
+	GitHubUsername  string
+	GitHubListing   string
+	Organizations   []string
+	Filters         FilterConfig
+	Layout          string
+	CloneProtocol   string
+	SSH             SSHConfig
+	MaxParallel     int
+	ShutdownTimeout time.Duration
+	CloneTimeout    time.Duration
+	UpdateTimeout   time.Duration
+	Retry           RetryConfig
+	FullRefresh     time.Duration
+	ReportPath      string
+	ReportRetention int
+	LFS             bool
+	Repositories    []RepositoryConfig
+	Sources         []SourceConfig
+}
+
+// SSHConfig is the SSH identity and the pinned host keys, keyed by host.
+type SSHConfig struct {
+	PrivateKeyPath string
+	KnownHosts     map[string][]string
+}
+
+// FilterConfig selects which listed repositories are mirrored. Patterns and
+// topics are lower case, MaxSizeKB is zero when there is no size limit.
+type FilterConfig struct {
+	Include          []string
+	Exclude          []string
+	ExcludeForks     bool
+	ExcludeArchived  bool
+	ExcludeTemplates bool
+	ExcludePrivate   bool
+	ExcludePublic    bool
+	IncludeTopics    []string
+	ExcludeTopics    []string
+	MaxSizeKB        int64
+}
+
+// RepositoryConfig overrides settings for the repositories matching Pattern,
+// a lower case full name or glob. Zero values and a nil LFS keep the global
+// setting.
+type RepositoryConfig struct {
+	Pattern       string
+	CloneTimeout  time.Duration
+	UpdateTimeout time.Duration
+	FetchInterval time.Duration
+	CloneProtocol string
+	FetchRefspecs []string
+	LFS           *bool
+	Retention     time.Duration
+}
+
+// Matches reports whether the override applies to a repository.
+func (r RepositoryConfig) Matches(fullName string) bool {
+	matched, _ := path.Match(r.Pattern, strings.ToLower(fullName))
+	return matched
+}
+
+// StaticRepository is a git remote listed by a static source. An empty Name
+// is derived from URL when the source is listed.
+type StaticRepository struct {
+	Name string
+	URL  string
+}
+
+// SourceConfig is a forge other than GitHub. BaseURL has no trailing slash.
+// Username is who the token authenticates as, where the forge needs one.
+// Users, Groups, Organizations, Workspaces and Projects select whose
+// repositories are listed, as far as the forge has them; without them a
+// source lists the repositories of the token's user. Static sources have
+// no API and list Repositories and the URLs in File instead.
+type SourceConfig struct {
+	Name          string
+	Type          string
+	BaseURL       string
+	Token         string
+	Username      string
+	Users         []string
+	Groups        []string
+	Organizations []string
+	Workspaces    []string
+	Projects      []string
+	Repositories  []StaticRepository
+	File          string
+}
+
+// RetryConfig is the retry policy applied to every clone and update.
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
 }
 
 var (
@@ -50,25 +178,480 @@ func Get() (*Config, error) {
 				return
 			}
 
-			if fileConfig.GitHubToken == "" {
-				loadErr = fmt.Errorf("[Config] GitHub token is either missing or empty")
-				return
+			instance, loadErr = newConfig(fileConfig)
+		},
+	)
+
+	return instance, loadErr
+}
+
+// newConfig validates the file configuration and fills in defaults.
+func newConfig(fileConfig *GitVaultFileConfig) (*Config, error) {
+	if fileConfig.GitHubToken == "" {
+		return nil, fmt.Errorf("[Config] GitHub token is either missing or empty")
+	}
+
+	if fileConfig.GitHubUsername == "" {
+		return nil, fmt.Errorf("[Config] GitHub Username is either missing or empty")
+	}
+
+	listing := fileConfig.GitHubListing
+	switch listing {
+	case "":
+		listing = GitHubListingUser
+	case GitHubListingUser, GitHubListingAuthenticated:
+	default:
+		return nil, fmt.Errorf("[Config] GitHub listing %q is not one of %q, %q", listing, GitHubListingUser, GitHubListingAuthenticated)
+	}
+
+	filters, err := newFilterConfig(fileConfig.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := validateLayout(fileConfig.Layout)
+	if err != nil {
+		return nil, fmt.Errorf("[Config] %w", err)
+	}
+
+	cloneProtocol := fileConfig.CloneProtocol
+	switch cloneProtocol {
+	case "":
+		cloneProtocol = CloneProtocolSSH
+	case CloneProtocolSSH, CloneProtocolHTTPS:
+	default:
+		return nil, fmt.Errorf("[Config] clone protocol %q is not one of %q, %q", cloneProtocol, CloneProtocolSSH, CloneProtocolHTTPS)
+	}
+
+	ssh, err := newSSHConfig(fileConfig.SSH)
+	if err != nil {
+		return nil, err
+	}
+
+	maxParallel := fileConfig.MaxParallel
+	if maxParallel < 0 {
+		return nil, fmt.Errorf("[Config] max_parallel must not be negative, got %d", maxParallel)
+	}
+	if maxParallel == 0 {
+		maxParallel = defaultMaxParallel
+	}
+
+	shutdownTimeout, err := durationOrDefault("shutdown_timeout", fileConfig.ShutdownTimeout, defaultShutdownTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	cloneTimeout, err := durationOrDefault("clone_timeout", fileConfig.CloneTimeout, defaultCloneTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	updateTimeout, err := durationOrDefault("update_timeout", fileConfig.UpdateTimeout, defaultUpdateTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	retry, err := newRetryConfig(fileConfig.Retry)
+	if err != nil {
+		return nil, err
+	}
+
+	fullRefresh, err := durationOrDefault("full_refresh_interval", fileConfig.FullRefresh, defaultFullRefreshInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	repositories, err := newRepositoryConfigs(fileConfig.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := newSourceConfigs(fileConfig.Sources)
+	if err != nil {
+		return nil, err
+	}
+
+	reportRetention := fileConfig.ReportRetention
+	if reportRetention < 0 {
+		return nil, fmt.Errorf("[Config] report_retention must not be negative, got %d", reportRetention)
+	}
+	if reportRetention == 0 {
+		reportRetention = defaultReportRetention
+	}
+
+	return &Config{
+		Version:         version,
+		GitHubToken:     fileConfig.GitHubToken,
+		GitHubUsername:  fileConfig.GitHubUsername,
+		GitHubListing:   listing,
+		Organizations:   fileConfig.Organizations,
+		Filters:         filters,
+		Layout:          layout,
+		CloneProtocol:   cloneProtocol,
+		SSH:             ssh,
+		MaxParallel:     maxParallel,
+		ShutdownTimeout: shutdownTimeout,
+		CloneTimeout:    cloneTimeout,
+		UpdateTimeout:   updateTimeout,
+		Retry:           retry,
+		FullRefresh:     fullRefresh,
+		ReportPath:      fileConfig.ReportPath,
+		ReportRetention: reportRetention,
+		LFS:             fileConfig.LFS,
+		Repositories:    repositories,
+		Sources:         sources,
+	}, nil
+}
+
+// newSSHConfig checks that every pinned host key is a "<type> <base64>" pair
+// for a host without whitespace, so the generated known_hosts file is valid.
+func newSSHConfig(fileConfig SSHFileConfig) (SSHConfig, error) {
+	ssh := SSHConfig{PrivateKeyPath: fileConfig.PrivateKeyPath}
+	if len(fileConfig.KnownHosts) == 0 {
+		return ssh, nil
+	}
+
+	ssh.KnownHosts = make(map[string][]string, len(fileConfig.KnownHosts))
+	for host, keys := range fileConfig.KnownHosts {
+		host = strings.TrimSpace(host)
+		if host == "" || strings.ContainsAny(host, " \t") {
+			return SSHConfig{}, fmt.Errorf("[Config] ssh.known_hosts host %q is not a valid host name", host)
+		}
+		if len(keys) == 0 {
+			return SSHConfig{}, fmt.Errorf("[Config] ssh.known_hosts must pin at least one key for %s", host)
+		}
+
+		for _, key := range keys {
+			fields := strings.Fields(key)
+			if len(fields) != 2 {
+				return SSHConfig{}, fmt.Errorf("[Config] ssh.known_hosts key %q for %s must be \"<type> <base64 key>\"", key, host)
 			}
+			ssh.KnownHosts[host] = append(ssh.KnownHosts[host], strings.Join(fields, " "))
+		}
+	}
+	return ssh, nil
+}
 
-			if fileConfig.GitHubUsername == "" {
-				loadErr = fmt.Errorf("[Config] GitHub Username is either missing or empty")
-				return
+// newFilterConfig rejects patterns that can never match and filters that
+// would skip every repository.
+func newFilterConfig(fileConfig FilterFileConfig) (FilterConfig, error) {
+	filters := FilterConfig{
+		ExcludeForks:     fileConfig.ExcludeForks,
+		ExcludeArchived:  fileConfig.ExcludeArchived,
+		ExcludeTemplates: fileConfig.ExcludeTemplates,
+		ExcludePrivate:   fileConfig.ExcludePrivate,
+		ExcludePublic:    fileConfig.ExcludePublic,
+		IncludeTopics:    lowerAll(fileConfig.IncludeTopics),
+		ExcludeTopics:    lowerAll(fileConfig.ExcludeTopics),
+	}
+
+	if err := validatePatterns("filters.include", fileConfig.Include); err != nil {
+		return FilterConfig{}, err
+	}
+	if err := validatePatterns("filters.exclude", fileConfig.Exclude); err != nil {
+		return FilterConfig{}, err
+	}
+	filters.Include = lowerAll(fileConfig.Include)
+	filters.Exclude = lowerAll(fileConfig.Exclude)
+
+	if filters.ExcludePrivate && filters.ExcludePublic {
+		return FilterConfig{}, fmt.Errorf("[Config] filters.exclude_private and filters.exclude_public together would skip every repository")
+	}
+
+	if fileConfig.MaxSizeMB < 0 {
+		return FilterConfig{}, fmt.Errorf("[Config] filters.max_size_mb must not be negative, got %d", fileConfig.MaxSizeMB)
+	}
+	filters.MaxSizeKB = int64(fileConfig.MaxSizeMB) * 1024
+
+	return filters, nil
+}
+
+// newRepositoryConfigs validates the per-repository overrides and orders
+// them from least to most specific: globs by length, then exact full names,
+// so that applying them in order lets the most specific one win.
+func newRepositoryConfigs(fileConfigs map[string]RepositoryFileConfig) ([]RepositoryConfig, error) {
+	var repositories []RepositoryConfig
+	for _, pattern := range slices.Sorted(maps.Keys(fileConfigs)) {
+		fileConfig := fileConfigs[pattern]
+		name := "repositories." + pattern
+		if pattern == "" {
+			return nil, fmt.Errorf("[Config] repositories must not have an empty key")
+		}
+		if err := validatePatterns("repositories", []string{pattern}); err != nil {
+			return nil, err
+		}
+
+		switch fileConfig.CloneProtocol {
+		case "", CloneProtocolSSH, CloneProtocolHTTPS:
+		default:
+			return nil, fmt.Errorf("[Config] %s.clone_protocol %q is not one of %q, %q", name, fileConfig.CloneProtocol, CloneProtocolSSH, CloneProtocolHTTPS)
+		}
+
+		cloneTimeout, err := durationOrDefault(name+".clone_timeout", fileConfig.CloneTimeout, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		updateTimeout, err := durationOrDefault(name+".update_timeout", fileConfig.UpdateTimeout, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		fetchInterval, err := durationOrDefault(name+".fetch_interval", fileConfig.FetchInterval, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		retention, err := durationOrDefault(name+".retention", fileConfig.Retention, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		repositories = append(repositories, RepositoryConfig{
+			Pattern:       strings.ToLower(pattern),
+			CloneTimeout:  cloneTimeout,
+			UpdateTimeout: updateTimeout,
+			FetchInterval: fetchInterval,
+			CloneProtocol: fileConfig.CloneProtocol,
+			FetchRefspecs: fileConfig.FetchRefspecs,
+			LFS:           fileConfig.LFS,
+			Retention:     retention,
+		})
+	}
+
+	slices.SortFunc(repositories, func(a, b RepositoryConfig) int {
+		return cmp.Or(
+			cmp.Compare(boolRank(!isGlob(a.Pattern)), boolRank(!isGlob(b.Pattern))),
+			cmp.Compare(len(a.Pattern), len(b.Pattern)),
+			strings.Compare(a.Pattern, b.Pattern),
+		)
+	})
+	return repositories, nil
+}
+
+// newSourceConfigs fills in source names and base URLs and checks that every
+// source has a unique name that can be used as a directory.
+func newSourceConfigs(fileConfigs []SourceFileConfig) ([]SourceConfig, error) {
+	var sources []SourceConfig
+	names := make(map[string]bool, len(fileConfigs))
+	for index, fileConfig := range fileConfigs {
+		field := fmt.Sprintf("sources[%d]", index)
+		sourceType, ok := sourceTypes[fileConfig.Type]
+		if !ok {
+			return nil, fmt.Errorf("[Config] %s.type %q is not one of %s", field, fileConfig.Type, quoteAll(slices.Sorted(maps.Keys(sourceTypes))))
+		}
+
+		name := cmp.Or(fileConfig.Name, fileConfig.Type)
+		if name == "." || !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("[Config] %s.name %q must be a single directory name", field, name)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("[Config] %s.name %q is used by another source", field, name)
+		}
+		names[strings.ToLower(name)] = true
+
+		var baseURL string
+		if sourceType.static {
+			if err := validateStaticSource(field, fileConfig); err != nil {
+				return nil, err
+			}
+		} else {
+			baseURL = strings.TrimRight(cmp.Or(fileConfig.BaseURL, sourceType.baseURL), "/")
+			if baseURL == "" {
+				return nil, fmt.Errorf("[Config] %s.base_url is required for %s sources", field, fileConfig.Type)
+			}
+			if parsed, err := url.Parse(baseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return nil, fmt.Errorf("[Config] %s.base_url %q must be an http or https URL", field, fileConfig.BaseURL)
 			}
 
-			instance = &Config{
-				Version:        version,
-				GitHubToken:    fileConfig.GitHubToken,
-				GitHubUsername: fileConfig.GitHubUsername,
+			if err := validateSourceListing(field, fileConfig, sourceType.selectors); err != nil {
+				return nil, err
 			}
-		},
-	)
+		}
 
-	return instance, loadErr
+		var repositories []StaticRepository
+		for _, repository := range fileConfig.Repositories {
+			repositories = append(repositories, StaticRepository{Name: repository.Name, URL: repository.URL})
+		}
+
+		sources = append(sources, SourceConfig{
+			Name:          name,
+			Type:          fileConfig.Type,
+			BaseURL:       baseURL,
+			Token:         fileConfig.Token,
+			Username:      fileConfig.Username,
+			Users:         fileConfig.Users,
+			Groups:        fileConfig.Groups,
+			Organizations: fileConfig.Organizations,
+			Workspaces:    fileConfig.Workspaces,
+			Projects:      fileConfig.Projects,
+			Repositories:  repositories,
+			File:          fileConfig.File,
+		})
+	}
+	return sources, nil
+}
+
+// validateSourceListing checks that a source selects repositories only with
+// the fields its forge understands, and selects at least some. Bitbucket
+// Cloud authenticates tokens together with a username.
+func validateSourceListing(field string, fileConfig SourceFileConfig, selectors []string) error {
+	values := map[string][]string{
+		"users":         fileConfig.Users,
+		"groups":        fileConfig.Groups,
+		"organizations": fileConfig.Organizations,
+		"workspaces":    fileConfig.Workspaces,
+		"projects":      fileConfig.Projects,
+	}
+
+	selected := false
+	for _, selector := range slices.Sorted(maps.Keys(values)) {
+		if len(values[selector]) == 0 {
+			continue
+		}
+		if !slices.Contains(selectors, selector) {
+			return fmt.Errorf("[Config] %s.%s is not supported by %s sources, use %s", field, selector, fileConfig.Type, strings.Join(selectors, " or "))
+		}
+		selected = true
+	}
+
+	if len(fileConfig.Repositories) > 0 || fileConfig.File != "" {
+		return fmt.Errorf("[Config] %s.repositories and file are only supported by static sources", field)
+	}
+	if fileConfig.Token == "" && !selected {
+		listing := append([]string{"a token"}, selectors...)
+		last := len(listing) - 1
+		return fmt.Errorf("[Config] %s needs %s or %s to list repositories", field, strings.Join(listing[:last], ", "), listing[last])
+	}
+	if fileConfig.Type == SourceTypeBitbucket && fileConfig.Token != "" && fileConfig.Username == "" {
+		return fmt.Errorf("[Config] %s.username is required with a token for bitbucket sources", field)
+	}
+	return nil
+}
+
+// validateStaticSource checks that a static source lists remotes and sets
+// nothing that only applies to forges. Its remotes authenticate over ssh or
+// are public.
+func validateStaticSource(field string, fileConfig SourceFileConfig) error {
+	switch {
+	case fileConfig.BaseURL != "":
+		return fmt.Errorf("[Config] %s.base_url is not supported by static sources", field)
+	case fileConfig.Token != "" || fileConfig.Username != "":
+		return fmt.Errorf("[Config] %s.token and username are not supported by static sources", field)
+	case len(fileConfig.Users) > 0 || len(fileConfig.Groups) > 0 || len(fileConfig.Organizations) > 0 || len(fileConfig.Workspaces) > 0 || len(fileConfig.Projects) > 0:
+		return fmt.Errorf("[Config] %s.users, groups, organizations, workspaces and projects are not supported by static sources", field)
+	case len(fileConfig.Repositories) == 0 && fileConfig.File == "":
+		return fmt.Errorf("[Config] %s needs repositories or a file to list", field)
+	}
+
+	for index, repository := range fileConfig.Repositories {
+		if repository.URL == "" {
+			return fmt.Errorf("[Config] %s.repositories[%d].url is required", field, index)
+		}
+	}
+	return nil
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[\\")
+}
+
+func boolRank(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func validatePatterns(name string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("[Config] %s pattern %q is invalid: %w", name, pattern, err)
+		}
+	}
+	return nil
+}
+
+func lowerAll(values []string) []string {
+	var lowered []string
+	for _, value := range values {
+		lowered = append(lowered, strings.ToLower(value))
+	}
+	return lowered
+}
+
+func newRetryConfig(fileConfig RetryFileConfig) (RetryConfig, error) {
+	maxAttempts := fileConfig.MaxAttempts
+	if maxAttempts < 0 {
+		return RetryConfig{}, fmt.Errorf("[Config] retry.max_attempts must not be negative, got %d", maxAttempts)
+	}
+	if maxAttempts == 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+
+	initialBackoff, err := durationOrDefault("retry.initial_backoff", fileConfig.InitialBackoff, defaultRetryInitialBackoff)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	maxBackoff, err := durationOrDefault("retry.max_backoff", fileConfig.MaxBackoff, max(defaultRetryMaxBackoff, initialBackoff))
+	if err != nil {
+		return RetryConfig{}, err
+	}
+	if maxBackoff < initialBackoff {
+		return RetryConfig{}, fmt.Errorf("[Config] retry.max_backoff %s must not be shorter than retry.initial_backoff %s", maxBackoff, initialBackoff)
+	}
+
+	return RetryConfig{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+	}, nil
+}
+
+func durationOrDefault(name string, value Duration, fallback time.Duration) (time.Duration, error) {
+	duration := time.Duration(value)
+	if duration < 0 {
+		return 0, fmt.Errorf("[Config] %s must not be negative, got %s", name, duration)
+	}
+	if duration == 0 {
+		return fallback, nil
+	}
+	return duration, nil
+}
+
+// validateLayout accepts the named layouts or a template built from
+// {owner}, {name}, {full_name} and {id} that identifies a repository
+// uniquely and stays inside the backup directory.
+func validateLayout(layout string) (string, error) {
+	switch layout {
+	case "":
+		return LayoutFlat, nil
+	case LayoutFlat, LayoutOwner:
+		return layout, nil
+	}
+
+	if !strings.Contains(layout, "{name}") && !strings.Contains(layout, "{full_name}") && !strings.Contains(layout, "{id}") {
+		return "", fmt.Errorf("layout %q must contain {name}, {full_name} or {id}", layout)
+	}
+
+	expanded := strings.NewReplacer("{owner}", "x", "{name}", "x", "{full_name}", "x/x", "{id}", "1").Replace(layout)
+	if strings.ContainsAny(expanded, "{}") {
+		return "", fmt.Errorf("layout %q contains an unknown placeholder", layout)
+	}
+	if !filepath.IsLocal(expanded) {
+		return "", fmt.Errorf("layout %q must be a relative path inside the backup directory", layout)
+	}
+
+	return layout, nil
 }
 
 func GetGitVaultVersion() string {
@@ -94,3 +677,139 @@ func GetGitHubUsername() string {
 
 	return instance.GitHubUsername
 }
+
+func GetGitHubListing() string {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.GitHubListing
+}
+
+func GetGitHubOrganizations() []string {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.Organizations
+}
+
+func GetFilters() FilterConfig {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.Filters
+}
+
+func GetLayout() string {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.Layout
+}
+
+func GetCloneProtocol() string {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.CloneProtocol
+}
+
+func GetSSH() SSHConfig {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.SSH
+}
+
+func GetMaxParallel() int {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.MaxParallel
+}
+
+func GetShutdownTimeout() time.Duration {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.ShutdownTimeout
+}
+
+func GetCloneTimeout() time.Duration {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.CloneTimeout
+}
+
+func GetUpdateTimeout() time.Duration {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.UpdateTimeout
+}
+
+func GetRetry() RetryConfig {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.Retry
+}
+
+func GetFullRefreshInterval() time.Duration {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.FullRefresh
+}
+
+func GetReportPath() string {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.ReportPath
+}
+
+func GetReportRetention() int {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.ReportRetention
+}
+
+func GetLFS() bool {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.LFS
+}
+
+func GetRepositoryOverrides() []RepositoryConfig {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.Repositories
+}
+
+func GetSources() []SourceConfig {
+	if instance == nil {
+		Get()
+	}
+
+	return instance.Sources
+}
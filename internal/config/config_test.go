@@ -4,6 +4,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -141,3 +142,671 @@ func TestGetGitHubUsername_Success(t *testing.T) {
 	username = GetGitHubUsername()
 	assert.Equal(t, "test-github-username", username)
 }
+
+func TestGet_DefaultsGitHubListing(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+	cfg, err := Get()
+
+	assert.NoError(t, err)
+	assert.Equal(t, GitHubListingUser, cfg.GitHubListing)
+}
+
+func TestGet_AuthenticatedGitHubListing(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		GitHubListing:  GitHubListingAuthenticated,
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, GitHubListingAuthenticated, GetGitHubListing())
+}
+
+func TestGet_InvalidGitHubListing(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		GitHubListing:  "everything",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+	cfg, err := Get()
+
+	assert.EqualError(t, err, `[Config] GitHub listing "everything" is not one of "user", "authenticated"`)
+	assert.True(t, cfg == nil)
+}
+
+func TestGetGitHubOrganizations_Success(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		Organizations:  []string{"acme", "globex"},
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, []string{"acme", "globex"}, GetGitHubOrganizations())
+}
+
+func TestGet_DefaultsLayout(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, LayoutFlat, GetLayout())
+}
+
+func TestValidateLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		layout      string
+		expected    string
+		expectedErr string
+	}{
+		{name: "empty defaults to flat", layout: "", expected: LayoutFlat},
+		{name: "flat", layout: LayoutFlat, expected: LayoutFlat},
+		{name: "owner", layout: LayoutOwner, expected: LayoutOwner},
+		{name: "owner and name template", layout: "{owner}/{name}.git", expected: "{owner}/{name}.git"},
+		{name: "id template", layout: "by-id/{id}.git", expected: "by-id/{id}.git"},
+		{name: "not unique", layout: "{owner}.git", expectedErr: `layout "{owner}.git" must contain {name}, {full_name} or {id}`},
+		{name: "unknown placeholder", layout: "{org}/{name}.git", expectedErr: `layout "{org}/{name}.git" contains an unknown placeholder`},
+		{name: "escapes backup directory", layout: "../{name}.git", expectedErr: `layout "../{name}.git" must be a relative path inside the backup directory`},
+		{name: "absolute path", layout: "/srv/{name}.git", expectedErr: `layout "/srv/{name}.git" must be a relative path inside the backup directory`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			layout, err := validateLayout(tc.layout)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, layout)
+		})
+	}
+}
+
+func TestGet_InvalidLayout(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		Layout:         "{owner}",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+	cfg, err := Get()
+
+	assert.EqualError(t, err, `[Config] layout "{owner}" must contain {name}, {full_name} or {id}`)
+	assert.True(t, cfg == nil)
+}
+
+func TestGet_DefaultsCloneProtocol(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, CloneProtocolSSH, GetCloneProtocol())
+}
+
+func TestGet_HTTPSCloneProtocol(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		CloneProtocol:  CloneProtocolHTTPS,
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, CloneProtocolHTTPS, GetCloneProtocol())
+}
+
+func TestGet_InvalidCloneProtocol(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		CloneProtocol:  "git",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+	cfg, err := Get()
+
+	assert.EqualError(t, err, `[Config] clone protocol "git" is not one of "ssh", "https"`)
+	assert.True(t, cfg == nil)
+}
+
+func TestGet_SSH(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		SSH: SSHFileConfig{
+			PrivateKeyPath: "/secrets/id_ed25519",
+			KnownHosts: map[string][]string{
+				" github.com ": {" ssh-ed25519  AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl "},
+			},
+		},
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, SSHConfig{
+		PrivateKeyPath: "/secrets/id_ed25519",
+		KnownHosts: map[string][]string{
+			"github.com": {"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"},
+		},
+	}, GetSSH())
+}
+
+func TestGet_InvalidSSHKnownHosts(t *testing.T) {
+	tests := []struct {
+		name       string
+		knownHosts map[string][]string
+		err        string
+	}{
+		{"empty host", map[string][]string{" ": {"ssh-ed25519 AAAA"}}, `[Config] ssh.known_hosts host "" is not a valid host name`},
+		{"host with space", map[string][]string{"git hub.com": {"ssh-ed25519 AAAA"}}, `[Config] ssh.known_hosts host "git hub.com" is not a valid host name`},
+		{"no keys", map[string][]string{"github.com": {}}, "[Config] ssh.known_hosts must pin at least one key for github.com"},
+		{"key without type", map[string][]string{"github.com": {"AAAA"}}, `[Config] ssh.known_hosts key "AAAA" for github.com must be "<type> <base64 key>"`},
+		{"key with host", map[string][]string{"github.com": {"github.com ssh-ed25519 AAAA"}}, `[Config] ssh.known_hosts key "github.com ssh-ed25519 AAAA" for github.com must be "<type> <base64 key>"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGitVaultConfig := &GitVaultFileConfig{
+				GitHubToken:    "test-github-token",
+				GitHubUsername: "test-github-username",
+				SSH:            SSHFileConfig{KnownHosts: tt.knownHosts},
+			}
+			mockConfig(t, mockGitVaultConfig, nil)
+			cfg, err := Get()
+
+			assert.EqualError(t, err, tt.err)
+			assert.True(t, cfg == nil)
+		})
+	}
+}
+
+func TestGet_DefaultsMaxParallel(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, defaultMaxParallel, GetMaxParallel())
+}
+
+func TestGet_MaxParallel(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		MaxParallel:    16,
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, 16, GetMaxParallel())
+}
+
+func TestGet_NegativeMaxParallel(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		MaxParallel:    -1,
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+	cfg, err := Get()
+
+	assert.EqualError(t, err, "[Config] max_parallel must not be negative, got -1")
+	assert.True(t, cfg == nil)
+}
+
+func TestGet_DefaultsShutdownTimeout(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, defaultShutdownTimeout, GetShutdownTimeout())
+}
+
+func TestGet_NegativeShutdownTimeout(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:     "test-github-token",
+		GitHubUsername:  "test-github-username",
+		ShutdownTimeout: Duration(-time.Second),
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+	cfg, err := Get()
+
+	assert.EqualError(t, err, "[Config] shutdown_timeout must not be negative, got -1s")
+	assert.True(t, cfg == nil)
+}
+
+func TestGet_DefaultsOperationTimeoutsAndRetry(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, defaultCloneTimeout, GetCloneTimeout())
+	assert.Equal(t, defaultUpdateTimeout, GetUpdateTimeout())
+	assert.Equal(t, RetryConfig{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+	}, GetRetry())
+}
+
+func TestGet_OperationTimeoutsAndRetry(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		CloneTimeout:   Duration(2 * time.Hour),
+		UpdateTimeout:  Duration(time.Minute),
+		Retry: RetryFileConfig{
+			MaxAttempts:    5,
+			InitialBackoff: Duration(time.Second),
+			MaxBackoff:     Duration(time.Minute),
+		},
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, 2*time.Hour, GetCloneTimeout())
+	assert.Equal(t, time.Minute, GetUpdateTimeout())
+	assert.Equal(t, RetryConfig{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute}, GetRetry())
+}
+
+func TestGet_LongInitialBackoffRaisesDefaultMaxBackoff(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		Retry:          RetryFileConfig{InitialBackoff: Duration(10 * time.Minute)},
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, 10*time.Minute, GetRetry().MaxBackoff)
+}
+
+func TestGet_InvalidRetry(t *testing.T) {
+	tests := []struct {
+		name  string
+		retry RetryFileConfig
+		err   string
+	}{
+		{"negative attempts", RetryFileConfig{MaxAttempts: -1}, "[Config] retry.max_attempts must not be negative, got -1"},
+		{"negative backoff", RetryFileConfig{InitialBackoff: Duration(-time.Second)}, "[Config] retry.initial_backoff must not be negative, got -1s"},
+		{"max below initial", RetryFileConfig{InitialBackoff: Duration(time.Minute), MaxBackoff: Duration(time.Second)}, "[Config] retry.max_backoff 1s must not be shorter than retry.initial_backoff 1m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGitVaultConfig := &GitVaultFileConfig{
+				GitHubToken:    "test-github-token",
+				GitHubUsername: "test-github-username",
+				Retry:          tt.retry,
+			}
+			mockConfig(t, mockGitVaultConfig, nil)
+			cfg, err := Get()
+
+			assert.EqualError(t, err, tt.err)
+			assert.True(t, cfg == nil)
+		})
+	}
+}
+
+func TestGet_DefaultsReportRetention(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, defaultReportRetention, GetReportRetention())
+	assert.Empty(t, GetReportPath())
+}
+
+func TestGet_Report(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:     "test-github-token",
+		GitHubUsername:  "test-github-username",
+		ReportPath:      "/metrics/gitvault.json",
+		ReportRetention: 7,
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, "/metrics/gitvault.json", GetReportPath())
+	assert.Equal(t, 7, GetReportRetention())
+}
+
+func TestGet_NegativeReportRetention(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:     "test-github-token",
+		GitHubUsername:  "test-github-username",
+		ReportRetention: -1,
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+	cfg, err := Get()
+
+	assert.EqualError(t, err, "[Config] report_retention must not be negative, got -1")
+	assert.True(t, cfg == nil)
+}
+
+func TestGet_FullRefreshInterval(t *testing.T) {
+	mockGitVaultConfig := &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+	}
+	mockConfig(t, mockGitVaultConfig, nil)
+
+	assert.Equal(t, defaultFullRefreshInterval, GetFullRefreshInterval())
+
+	reset()
+	mockGitVaultConfig.FullRefresh = Duration(6 * time.Hour)
+
+	assert.Equal(t, 6*time.Hour, GetFullRefreshInterval())
+}
+
+func TestGet_Filters(t *testing.T) {
+	mockConfig(t, &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		Filters: FilterFileConfig{
+			Include:         []string{"Acme/*"},
+			Exclude:         []string{"*/Dataset-*"},
+			ExcludeArchived: true,
+			IncludeTopics:   []string{"Backup"},
+			MaxSizeMB:       2,
+		},
+	}, nil)
+
+	assert.Equal(t, FilterConfig{
+		Include:         []string{"acme/*"},
+		Exclude:         []string{"*/dataset-*"},
+		ExcludeArchived: true,
+		IncludeTopics:   []string{"backup"},
+		MaxSizeKB:       2048,
+	}, GetFilters())
+}
+
+func TestGet_DefaultsFilters(t *testing.T) {
+	mockConfig(t, &GitVaultFileConfig{GitHubToken: "test-github-token", GitHubUsername: "test-github-username"}, nil)
+
+	assert.Equal(t, FilterConfig{}, GetFilters())
+}
+
+func TestGet_InvalidFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  FilterFileConfig
+		expected string
+	}{
+		{
+			name:     "invalid include pattern",
+			filters:  FilterFileConfig{Include: []string{"acme/["}},
+			expected: `[Config] filters.include pattern "acme/[" is invalid: syntax error in pattern`,
+		},
+		{
+			name:     "invalid exclude pattern",
+			filters:  FilterFileConfig{Exclude: []string{"[a-"}},
+			expected: `[Config] filters.exclude pattern "[a-" is invalid: syntax error in pattern`,
+		},
+		{
+			name:     "private and public excluded",
+			filters:  FilterFileConfig{ExcludePrivate: true, ExcludePublic: true},
+			expected: "[Config] filters.exclude_private and filters.exclude_public together would skip every repository",
+		},
+		{
+			name:     "negative max size",
+			filters:  FilterFileConfig{MaxSizeMB: -1},
+			expected: "[Config] filters.max_size_mb must not be negative, got -1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockConfig(t, &GitVaultFileConfig{
+				GitHubToken:    "test-github-token",
+				GitHubUsername: "test-github-username",
+				Filters:        tc.filters,
+			}, nil)
+
+			cfg, err := Get()
+
+			assert.EqualError(t, err, tc.expected)
+			assert.Nil(t, cfg)
+		})
+	}
+}
+
+func TestGet_RepositoryOverrides(t *testing.T) {
+	lfs := true
+	mockConfig(t, &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		LFS:            true,
+		Repositories: map[string]RepositoryFileConfig{
+			"acme/Monorepo": {CloneTimeout: Duration(3 * time.Hour), LFS: &lfs},
+			"acme/*":        {CloneProtocol: CloneProtocolHTTPS},
+			"*/*":           {Retention: Duration(time.Hour)},
+			"acme/vendor-*": {FetchInterval: Duration(7 * 24 * time.Hour), FetchRefspecs: []string{"+refs/notes/*:refs/notes/*"}},
+		},
+	}, nil)
+
+	assert.True(t, GetLFS())
+	assert.Equal(t, []RepositoryConfig{
+		{Pattern: "*/*", Retention: time.Hour},
+		{Pattern: "acme/*", CloneProtocol: CloneProtocolHTTPS},
+		{Pattern: "acme/vendor-*", FetchInterval: 7 * 24 * time.Hour, FetchRefspecs: []string{"+refs/notes/*:refs/notes/*"}},
+		{Pattern: "acme/monorepo", CloneTimeout: 3 * time.Hour, LFS: &lfs},
+	}, GetRepositoryOverrides())
+}
+
+func TestRepositoryConfig_Matches(t *testing.T) {
+	assert.True(t, RepositoryConfig{Pattern: "acme/*"}.Matches("Acme/Tools"))
+	assert.True(t, RepositoryConfig{Pattern: "acme/tools"}.Matches("acme/tools"))
+	assert.False(t, RepositoryConfig{Pattern: "acme/*"}.Matches("alice/tools"))
+}
+
+func TestGet_InvalidRepositoryOverrides(t *testing.T) {
+	tests := []struct {
+		name         string
+		repositories map[string]RepositoryFileConfig
+		expected     string
+	}{
+		{
+			name:         "empty key",
+			repositories: map[string]RepositoryFileConfig{"": {}},
+			expected:     "[Config] repositories must not have an empty key",
+		},
+		{
+			name:         "invalid pattern",
+			repositories: map[string]RepositoryFileConfig{"acme/[": {}},
+			expected:     `[Config] repositories pattern "acme/[" is invalid: syntax error in pattern`,
+		},
+		{
+			name:         "invalid clone protocol",
+			repositories: map[string]RepositoryFileConfig{"acme/tools": {CloneProtocol: "ftp"}},
+			expected:     `[Config] repositories.acme/tools.clone_protocol "ftp" is not one of "ssh", "https"`,
+		},
+		{
+			name:         "negative timeout",
+			repositories: map[string]RepositoryFileConfig{"acme/tools": {UpdateTimeout: Duration(-time.Second)}},
+			expected:     "[Config] repositories.acme/tools.update_timeout must not be negative, got -1s",
+		},
+		{
+			name:         "negative retention",
+			repositories: map[string]RepositoryFileConfig{"acme/tools": {Retention: Duration(-time.Hour)}},
+			expected:     "[Config] repositories.acme/tools.retention must not be negative, got -1h0m0s",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockConfig(t, &GitVaultFileConfig{
+				GitHubToken:    "test-github-token",
+				GitHubUsername: "test-github-username",
+				Repositories:   tc.repositories,
+			}, nil)
+
+			cfg, err := Get()
+
+			assert.EqualError(t, err, tc.expected)
+			assert.Nil(t, cfg)
+		})
+	}
+}
+
+func TestGet_Sources(t *testing.T) {
+	mockConfig(t, &GitVaultFileConfig{
+		GitHubToken:    "test-github-token",
+		GitHubUsername: "test-github-username",
+		Sources: []SourceFileConfig{
+			{Type: SourceTypeGitLab, Token: "glpat-token"},
+			{Name: "work", Type: SourceTypeGitLab, BaseURL: "https://gitlab.example.com/", Users: []string{"alice"}, Groups: []string{"platform"}},
+			{Name: "homelab", Type: SourceTypeGitea, BaseURL: "http://forgejo.lan:3000", Organizations: []string{"infra"}},
+			{Type: SourceTypeBitbucket, Username: "alice", Token: "app-password", Workspaces: []string{"acme"}},
+			{Name: "datacenter", Type: SourceTypeBitbucketServer, BaseURL: "https://bitbucket.example.com/", Token: "http-token", Projects: []string{"PLAT"}},
+			{Name: "upstream", Type: SourceTypeStatic, Repositories: []StaticRepositoryFileConfig{{Name: "linux", URL: "https://github.com/torvalds/linux.git"}}, File: "/config/upstream.txt"},
+		},
+	}, nil)
+
+	assert.Equal(t, []SourceConfig{
+		{Name: "gitlab", Type: SourceTypeGitLab, BaseURL: "https://gitlab.com", Token: "glpat-token"},
+		{Name: "work", Type: SourceTypeGitLab, BaseURL: "https://gitlab.example.com", Users: []string{"alice"}, Groups: []string{"platform"}},
+		{Name: "homelab", Type: SourceTypeGitea, BaseURL: "http://forgejo.lan:3000", Organizations: []string{"infra"}},
+		{Name: "bitbucket", Type: SourceTypeBitbucket, BaseURL: "https://api.bitbucket.org", Username: "alice", Token: "app-password", Workspaces: []string{"acme"}},
+		{Name: "datacenter", Type: SourceTypeBitbucketServer, BaseURL: "https://bitbucket.example.com", Token: "http-token", Projects: []string{"PLAT"}},
+		{Name: "upstream", Type: SourceTypeStatic, Repositories: []StaticRepository{{Name: "linux", URL: "https://github.com/torvalds/linux.git"}}, File: "/config/upstream.txt"},
+	}, GetSources())
+}
+
+func TestGet_InvalidSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		sources  []SourceFileConfig
+		expected string
+	}{
+		{
+			name:     "unknown type",
+			sources:  []SourceFileConfig{{Type: "svn", Token: "token"}},
+			expected: `[Config] sources[0].type "svn" is not one of "bitbucket", "bitbucket-server", "gitea", "gitlab", "static"`,
+		},
+		{
+			name:     "name with a slash",
+			sources:  []SourceFileConfig{{Name: "gitlab/work", Type: SourceTypeGitLab, Token: "token"}},
+			expected: `[Config] sources[0].name "gitlab/work" must be a single directory name`,
+		},
+		{
+			name:     "name outside the backup directory",
+			sources:  []SourceFileConfig{{Name: "..", Type: SourceTypeGitLab, Token: "token"}},
+			expected: `[Config] sources[0].name ".." must be a single directory name`,
+		},
+		{
+			name: "duplicate name",
+			sources: []SourceFileConfig{
+				{Type: SourceTypeGitLab, Token: "token"},
+				{Name: "GitLab", Type: SourceTypeGitLab, BaseURL: "https://gitlab.example.com", Token: "token"},
+			},
+			expected: `[Config] sources[1].name "GitLab" is used by another source`,
+		},
+		{
+			name:     "invalid base URL",
+			sources:  []SourceFileConfig{{Type: SourceTypeGitLab, BaseURL: "gitlab.example.com", Token: "token"}},
+			expected: `[Config] sources[0].base_url "gitlab.example.com" must be an http or https URL`,
+		},
+		{
+			name:     "nothing to list",
+			sources:  []SourceFileConfig{{Type: SourceTypeGitLab}},
+			expected: "[Config] sources[0] needs a token, users or groups to list repositories",
+		},
+		{
+			name:     "organizations on gitlab",
+			sources:  []SourceFileConfig{{Type: SourceTypeGitLab, Organizations: []string{"platform"}}},
+			expected: "[Config] sources[0].organizations is not supported by gitlab sources, use users or groups",
+		},
+		{
+			name:     "gitea without base URL",
+			sources:  []SourceFileConfig{{Type: SourceTypeGitea, Token: "token"}},
+			expected: "[Config] sources[0].base_url is required for gitea sources",
+		},
+		{
+			name:     "groups on gitea",
+			sources:  []SourceFileConfig{{Type: SourceTypeGitea, BaseURL: "https://git.example.com", Groups: []string{"homelab"}}},
+			expected: "[Config] sources[0].groups is not supported by gitea sources, use organizations",
+		},
+		{
+			name:     "nothing to list on gitea",
+			sources:  []SourceFileConfig{{Type: SourceTypeGitea, BaseURL: "https://git.example.com"}},
+			expected: "[Config] sources[0] needs a token or organizations to list repositories",
+		},
+		{
+			name:     "bitbucket token without username",
+			sources:  []SourceFileConfig{{Type: SourceTypeBitbucket, Token: "app-password"}},
+			expected: "[Config] sources[0].username is required with a token for bitbucket sources",
+		},
+		{
+			name:     "groups on bitbucket",
+			sources:  []SourceFileConfig{{Type: SourceTypeBitbucket, Groups: []string{"acme"}}},
+			expected: "[Config] sources[0].groups is not supported by bitbucket sources, use workspaces",
+		},
+		{
+			name:     "bitbucket server without base URL",
+			sources:  []SourceFileConfig{{Type: SourceTypeBitbucketServer, Token: "http-token"}},
+			expected: "[Config] sources[0].base_url is required for bitbucket-server sources",
+		},
+		{
+			name:     "nothing to list on bitbucket server",
+			sources:  []SourceFileConfig{{Type: SourceTypeBitbucketServer, BaseURL: "https://bitbucket.example.com"}},
+			expected: "[Config] sources[0] needs a token or projects to list repositories",
+		},
+		{
+			name:     "static without repositories",
+			sources:  []SourceFileConfig{{Type: SourceTypeStatic}},
+			expected: "[Config] sources[0] needs repositories or a file to list",
+		},
+		{
+			name:     "static repository without URL",
+			sources:  []SourceFileConfig{{Type: SourceTypeStatic, Repositories: []StaticRepositoryFileConfig{{Name: "linux"}}}},
+			expected: "[Config] sources[0].repositories[0].url is required",
+		},
+		{
+			name:     "static with base URL",
+			sources:  []SourceFileConfig{{Type: SourceTypeStatic, BaseURL: "https://git.example.com", File: "/config/upstream.txt"}},
+			expected: "[Config] sources[0].base_url is not supported by static sources",
+		},
+		{
+			name:     "static with token",
+			sources:  []SourceFileConfig{{Type: SourceTypeStatic, Token: "token", File: "/config/upstream.txt"}},
+			expected: "[Config] sources[0].token and username are not supported by static sources",
+		},
+		{
+			name:     "static with groups",
+			sources:  []SourceFileConfig{{Type: SourceTypeStatic, Groups: []string{"platform"}, File: "/config/upstream.txt"}},
+			expected: "[Config] sources[0].users, groups, organizations, workspaces and projects are not supported by static sources",
+		},
+		{
+			name:     "repositories on gitlab",
+			sources:  []SourceFileConfig{{Type: SourceTypeGitLab, Token: "token", File: "/config/upstream.txt"}},
+			expected: "[Config] sources[0].repositories and file are only supported by static sources",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockConfig(t, &GitVaultFileConfig{
+				GitHubToken:    "test-github-token",
+				GitHubUsername: "test-github-username",
+				Sources:        tc.sources,
+			}, nil)
+
+			cfg, err := Get()
+
+			assert.EqualError(t, err, tc.expected)
+			assert.Nil(t, cfg)
+		})
+	}
+}
@@ -2,13 +2,145 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 )
 
+const (
+	GitHubListingUser          = "user"
+	GitHubListingAuthenticated = "authenticated"
+)
+
+const (
+	LayoutFlat  = "flat"
+	LayoutOwner = "owner"
+)
+
+const (
+	CloneProtocolSSH   = "ssh"
+	CloneProtocolHTTPS = "https"
+)
+
+const (
+	SourceTypeGitLab          = "gitlab"
+	SourceTypeGitea           = "gitea"
+	SourceTypeBitbucket       = "bitbucket"
+	SourceTypeBitbucketServer = "bitbucket-server"
+	SourceTypeStatic          = "static"
+)
+
+// Duration reads a Go duration string such as "90s" or "1h30m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// RetryFileConfig controls how failed git operations are retried.
+type RetryFileConfig struct {
+	MaxAttempts    int      `json:"max_attempts"`
+	InitialBackoff Duration `json:"initial_backoff"`
+	MaxBackoff     Duration `json:"max_backoff"`
+}
+
+// SSHFileConfig pins the identity and host keys used for SSH cloning.
+// KnownHosts maps a host, or [host]:port, to its "<type> <base64>" keys.
+type SSHFileConfig struct {
+	PrivateKeyPath string              `json:"private_key_path"`
+	KnownHosts     map[string][]string `json:"known_hosts"`
+}
+
+// FilterFileConfig selects which listed repositories are mirrored. Include
+// and Exclude are glob patterns on the full name, such as "acme/*".
+type FilterFileConfig struct {
+	Include          []string `json:"include"`
+	Exclude          []string `json:"exclude"`
+	ExcludeForks     bool     `json:"exclude_forks"`
+	ExcludeArchived  bool     `json:"exclude_archived"`
+	ExcludeTemplates bool     `json:"exclude_templates"`
+	ExcludePrivate   bool     `json:"exclude_private"`
+	ExcludePublic    bool     `json:"exclude_public"`
+	IncludeTopics    []string `json:"include_topics"`
+	ExcludeTopics    []string `json:"exclude_topics"`
+	MaxSizeMB        int      `json:"max_size_mb"`
+}
+
+// RepositoryFileConfig overrides settings for the repositories whose full
+// name matches its key, either exactly or as a glob such as "acme/*". Unset
+// fields keep the global value.
+type RepositoryFileConfig struct {
+	CloneTimeout  Duration `json:"clone_timeout"`
+	UpdateTimeout Duration `json:"update_timeout"`
+	FetchInterval Duration `json:"fetch_interval"`
+	CloneProtocol string   `json:"clone_protocol"`
+	FetchRefspecs []string `json:"fetch_refspecs"`
+	LFS           *bool    `json:"lfs"`
+	Retention     Duration `json:"retention"`
+}
+
+// StaticRepositoryFileConfig is a git remote listed by a static source. Name
+// defaults to the path of URL.
+type StaticRepositoryFileConfig struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// SourceFileConfig is a forge other than GitHub, or a static list of
+// remotes, whose repositories are mirrored. Name defaults to Type and names
+// the directory its mirrors are kept in. File is a file of remote URLs, one
+// per line, that a static source lists as well.
+type SourceFileConfig struct {
+	Name          string                       `json:"name"`
+	Type          string                       `json:"type"`
+	BaseURL       string                       `json:"base_url"`
+	Token         string                       `json:"token"`
+	Username      string                       `json:"username"`
+	Users         []string                     `json:"users"`
+	Groups        []string                     `json:"groups"`
+	Organizations []string                     `json:"organizations"`
+	Workspaces    []string                     `json:"workspaces"`
+	Projects      []string                     `json:"projects"`
+	Repositories  []StaticRepositoryFileConfig `json:"repositories"`
+	File          string                       `json:"file"`
+}
+
 type GitVaultFileConfig struct {
-	GitHubToken    string `json:"github_token"`
-	GitHubUsername string `json:"github_username"`
+	GitHubToken     string                          `json:"github_token"`
+	GitHubUsername  string                          `json:"github_username"`
+	GitHubListing   string                          `json:"github_listing"`
+	Organizations   []string                        `json:"organizations"`
+	Filters         FilterFileConfig                `json:"filters"`
+	Layout          string                          `json:"layout"`
+	CloneProtocol   string                          `json:"clone_protocol"`
+	SSH             SSHFileConfig                   `json:"ssh"`
+	MaxParallel     int                             `json:"max_parallel"`
+	ShutdownTimeout Duration                        `json:"shutdown_timeout"`
+	CloneTimeout    Duration                        `json:"clone_timeout"`
+	UpdateTimeout   Duration                        `json:"update_timeout"`
+	Retry           RetryFileConfig                 `json:"retry"`
+	FullRefresh     Duration                        `json:"full_refresh_interval"`
+	ReportPath      string                          `json:"report_path"`
+	ReportRetention int                             `json:"report_retention"`
+	LFS             bool                            `json:"lfs"`
+	Repositories    map[string]RepositoryFileConfig `json:"repositories"`
+	Sources         []SourceFileConfig              `json:"sources"`
 }
 
 func LoadConfig(filepath string) (*GitVaultFileConfig, error) {
@@ -25,6 +157,65 @@ func LoadConfig(filepath string) (*GitVaultFileConfig, error) {
 
 	cfg.GitHubToken = strings.TrimSpace(cfg.GitHubToken)
 	cfg.GitHubUsername = strings.TrimSpace(cfg.GitHubUsername)
+	cfg.GitHubListing = strings.TrimSpace(cfg.GitHubListing)
+	cfg.Layout = strings.TrimSpace(cfg.Layout)
+	cfg.CloneProtocol = strings.TrimSpace(cfg.CloneProtocol)
+	cfg.SSH.PrivateKeyPath = strings.TrimSpace(cfg.SSH.PrivateKeyPath)
+	cfg.ReportPath = strings.TrimSpace(cfg.ReportPath)
+
+	organizations := make([]string, 0, len(cfg.Organizations))
+	for _, organization := range cfg.Organizations {
+		if organization = strings.TrimSpace(organization); organization != "" {
+			organizations = append(organizations, organization)
+		}
+	}
+	cfg.Organizations = organizations
+
+	cfg.Filters.Include = trimAll(cfg.Filters.Include)
+	cfg.Filters.Exclude = trimAll(cfg.Filters.Exclude)
+	cfg.Filters.IncludeTopics = trimAll(cfg.Filters.IncludeTopics)
+	cfg.Filters.ExcludeTopics = trimAll(cfg.Filters.ExcludeTopics)
+
+	if cfg.Repositories != nil {
+		repositories := make(map[string]RepositoryFileConfig, len(cfg.Repositories))
+		for pattern, repository := range cfg.Repositories {
+			repository.CloneProtocol = strings.TrimSpace(repository.CloneProtocol)
+			repository.FetchRefspecs = trimAll(repository.FetchRefspecs)
+			repositories[strings.TrimSpace(pattern)] = repository
+		}
+		cfg.Repositories = repositories
+	}
+
+	for index := range cfg.Sources {
+		source := &cfg.Sources[index]
+		source.Name = strings.TrimSpace(source.Name)
+		source.Type = strings.TrimSpace(source.Type)
+		source.BaseURL = strings.TrimSpace(source.BaseURL)
+		source.Token = strings.TrimSpace(source.Token)
+		source.Username = strings.TrimSpace(source.Username)
+		source.Users = trimAll(source.Users)
+		source.Groups = trimAll(source.Groups)
+		source.Organizations = trimAll(source.Organizations)
+		source.Workspaces = trimAll(source.Workspaces)
+		source.Projects = trimAll(source.Projects)
+		source.File = strings.TrimSpace(source.File)
+		for index := range source.Repositories {
+			repository := &source.Repositories[index]
+			repository.Name = strings.TrimSpace(repository.Name)
+			repository.URL = strings.TrimSpace(repository.URL)
+		}
+	}
 
 	return &cfg, nil
 }
+
+// trimAll trims every value and drops the empty ones.
+func trimAll(values []string) []string {
+	var trimmed []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -151,3 +152,153 @@ func TestLoadConfig_ExtraFieldsIgnored(t *testing.T) {
 	assert.Equal(t, cfg.GitHubToken, token)
 	assert.Equal(t, cfg.GitHubUsername, username)
 }
+
+func TestLoadConfig_GitHubListing(t *testing.T) {
+	path := "/tmp/github_listing.json"
+	jsonData := `{"github_token": "token", "github_username": "user", "github_listing": " authenticated "}`
+	setupFile(t, path, []byte(jsonData))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, GitHubListingAuthenticated, cfg.GitHubListing)
+}
+
+func TestLoadConfig_Organizations(t *testing.T) {
+	path := "/tmp/organizations.json"
+	jsonData := `{"github_token": "token", "github_username": "user", "organizations": [" acme ", "", "\t", "globex"]}`
+	setupFile(t, path, []byte(jsonData))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"acme", "globex"}, cfg.Organizations)
+}
+
+func TestLoadConfig_Duration(t *testing.T) {
+	path := "/tmp/duration.json"
+	setupFile(t, path, []byte(`{"shutdown_timeout": " 1m30s "}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Duration(90*time.Second), cfg.ShutdownTimeout)
+}
+
+func TestLoadConfig_Retry(t *testing.T) {
+	path := "/tmp/retry.json"
+	setupFile(t, path, []byte(`{"update_timeout": "10m", "retry": {"max_attempts": 4, "initial_backoff": "5s", "max_backoff": "1m"}}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Duration(10*time.Minute), cfg.UpdateTimeout)
+	assert.Equal(t, RetryFileConfig{MaxAttempts: 4, InitialBackoff: Duration(5 * time.Second), MaxBackoff: Duration(time.Minute)}, cfg.Retry)
+}
+
+func TestLoadConfig_SSH(t *testing.T) {
+	path := "/tmp/ssh.json"
+	setupFile(t, path, []byte(`{"ssh": {"private_key_path": " /secrets/id_ed25519 ", "known_hosts": {"github.com": ["ssh-ed25519 AAAA"]}}}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SSHFileConfig{
+		PrivateKeyPath: "/secrets/id_ed25519",
+		KnownHosts:     map[string][]string{"github.com": {"ssh-ed25519 AAAA"}},
+	}, cfg.SSH)
+}
+
+func TestLoadConfig_Filters(t *testing.T) {
+	path := "/tmp/filters.json"
+	setupFile(t, path, []byte(`{"filters": {"include": [" acme/* ", ""], "exclude": ["*/dataset-*"], "exclude_forks": true, "exclude_topics": [" demo "], "max_size_mb": 500}}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, FilterFileConfig{
+		Include:       []string{"acme/*"},
+		Exclude:       []string{"*/dataset-*"},
+		ExcludeForks:  true,
+		ExcludeTopics: []string{"demo"},
+		MaxSizeMB:     500,
+	}, cfg.Filters)
+}
+
+func TestLoadConfig_Repositories(t *testing.T) {
+	path := "/tmp/repositories.json"
+	setupFile(t, path, []byte(`{"lfs": true, "repositories": {" acme/monorepo ": {"update_timeout": "2h", "clone_protocol": " https ", "fetch_refspecs": [" +refs/notes/*:refs/notes/* ", ""], "lfs": false}}}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.True(t, cfg.LFS)
+	lfs := false
+	assert.Equal(t, map[string]RepositoryFileConfig{
+		"acme/monorepo": {
+			UpdateTimeout: Duration(2 * time.Hour),
+			CloneProtocol: CloneProtocolHTTPS,
+			FetchRefspecs: []string{"+refs/notes/*:refs/notes/*"},
+			LFS:           &lfs,
+		},
+	}, cfg.Repositories)
+}
+
+func TestLoadConfig_Sources(t *testing.T) {
+	path := "/tmp/sources.json"
+	setupFile(t, path, []byte(`{"sources": [{"name": " work ", "type": " gitlab ", "base_url": " https://gitlab.example.com/ ", "token": " glpat-token ", "users": [" alice ", ""], "groups": [" platform/tools "]}, {"type": "gitea", "organizations": [" infra "]}, {"type": "bitbucket", "username": " alice ", "workspaces": [" acme "]}, {"type": "bitbucket-server", "projects": [" PLAT "]}, {"type": "static", "repositories": [{"name": " linux ", "url": " https://github.com/torvalds/linux.git "}], "file": " /config/upstream.txt "}]}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SourceFileConfig{{
+		Name:    "work",
+		Type:    SourceTypeGitLab,
+		BaseURL: "https://gitlab.example.com/",
+		Token:   "glpat-token",
+		Users:   []string{"alice"},
+		Groups:  []string{"platform/tools"},
+	}, {
+		Type:          SourceTypeGitea,
+		Organizations: []string{"infra"},
+	}, {
+		Type:       SourceTypeBitbucket,
+		Username:   "alice",
+		Workspaces: []string{"acme"},
+	}, {
+		Type:     SourceTypeBitbucketServer,
+		Projects: []string{"PLAT"},
+	}, {
+		Type:         SourceTypeStatic,
+		Repositories: []StaticRepositoryFileConfig{{Name: "linux", URL: "https://github.com/torvalds/linux.git"}},
+		File:         "/config/upstream.txt",
+	}}, cfg.Sources)
+}
+
+func TestLoadConfig_InvalidDuration(t *testing.T) {
+	path := "/tmp/invalid_duration.json"
+	setupFile(t, path, []byte(`{"shutdown_timeout": "soon"}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.Nil(t, cfg)
+	assert.ErrorContains(t, err, `invalid duration "soon"`)
+}
+
+func TestLoadConfig_WrongTypeOfDuration(t *testing.T) {
+	path := "/tmp/wrong_type_duration.json"
+	setupFile(t, path, []byte(`{"shutdown_timeout": 30}`))
+
+	cfg, err := LoadConfig(path)
+
+	assert.Nil(t, cfg)
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+}
+
+func TestDuration_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(Duration(90 * time.Second))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"1m30s"`, string(data))
+}
@@ -2,46 +2,172 @@ package db
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 )
 
 const lockfilePath = "gitvault.lock.json"
 
+// SchemaVersion is the lockfile format written by this release. Version 1
+// files, which only held repository names, are migrated when read.
+const SchemaVersion = 2
+
+const (
+	StateActive      = "active"
+	StateSoftDeleted = "soft_deleted"
+)
+
 type DB struct {
+	Version int    `json:"version"`
+	Github  GitHub `json:"github"`
+}
+
+type GitHub struct {
+	Repositories []Repository `json:"repositories"`
+}
+
+// Repository is everything GitVault remembers about one mirrored repository.
+// Source names the configured source it is listed on and is empty for
+// GitHub; IDs are only unique within a source. Directory is relative to the
+// backup directory. PushedAt and UpdatedAt are the forge timestamps as of the
+// last fetch, LastFetchedAt is when that fetch happened.
+type Repository struct {
+	Source        string            `json:"source,omitempty"`
+	ID            int64             `json:"id,omitempty"`
+	FullName      string            `json:"full_name"`
+	CloneURL      string            `json:"clone_url,omitempty"`
+	Directory     string            `json:"directory,omitempty"`
+	State         string            `json:"state"`
+	FirstSeenAt   time.Time         `json:"first_seen_at,omitzero"`
+	LastSeenAt    time.Time         `json:"last_seen_at,omitzero"`
+	LastSyncedAt  time.Time         `json:"last_synced_at,omitzero"`
+	LastFetchedAt time.Time         `json:"last_fetched_at,omitzero"`
+	PushedAt      time.Time         `json:"pushed_at,omitzero"`
+	UpdatedAt     time.Time         `json:"updated_at,omitzero"`
+	DeletedAt     time.Time         `json:"deleted_at,omitzero"`
+	LastError     string            `json:"last_error,omitempty"`
+	LastErrorAt   time.Time         `json:"last_error_at,omitzero"`
+	FailureCount  int               `json:"failure_count,omitempty"`
+	Refs          map[string]string `json:"refs,omitempty"`
+}
+
+// legacyDB is the version 1 layout: bare repository names plus the
+// soft-deleted list that preceded per-repository states.
+type legacyDB struct {
 	Github struct {
 		Repositories []string `json:"repositories"`
+		SoftDeleted  []struct {
+			FullName  string    `json:"full_name"`
+			Directory string    `json:"directory"`
+			DeletedAt time.Time `json:"deleted_at"`
+		} `json:"soft_deleted"`
 	} `json:"github"`
 }
 
+func getLockfilePath() string {
+	if path := os.Getenv("GITVAULT_LOCKFILE_PATH"); path != "" {
+		return path
+	}
+	return lockfilePath
+}
+
+// LockfilePath returns the path of the lockfile, which other state such as run
+// reports is kept next to.
+func LockfilePath() string {
+	return getLockfilePath()
+}
+
 func getDB(filepath string) (*DB, error) {
-	file, err := os.Open(filepath)
+	db, _, err := readDB(filepath)
+	return db, err
+}
+
+// readDB loads the lockfile in the current schema and reports the version it
+// was stored with.
+func readDB(filepath string) (*DB, int, error) {
+	data, err := os.ReadFile(filepath)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	defer file.Close()
 
-	var db DB
-	if err := json.NewDecoder(file).Decode(&db); err != nil {
-		return nil, err
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, 0, err
+	}
+
+	var db *DB
+	switch {
+	case header.Version < SchemaVersion:
+		if db, err = migrateLegacyDB(data); err != nil {
+			return nil, 0, err
+		}
+	case header.Version == SchemaVersion:
+		db = &DB{}
+		if err := json.Unmarshal(data, db); err != nil {
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, fmt.Errorf("lockfile version %d is newer than supported version %d", header.Version, SchemaVersion)
 	}
 
 	if db.Github.Repositories == nil {
-		db.Github.Repositories = []string{}
+		db.Github.Repositories = []Repository{}
 	}
 
 	for index := range db.Github.Repositories {
-		db.Github.Repositories[index] = strings.TrimSpace(db.Github.Repositories[index])
+		db.Github.Repositories[index].FullName = strings.TrimSpace(db.Github.Repositories[index].FullName)
+	}
+
+	return db, header.Version, nil
+}
+
+func migrateLegacyDB(data []byte) (*DB, error) {
+	var legacy legacyDB
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, err
+	}
+
+	db := &DB{Version: SchemaVersion}
+	db.Github.Repositories = make([]Repository, 0, len(legacy.Github.Repositories)+len(legacy.Github.SoftDeleted))
+
+	for _, fullName := range legacy.Github.Repositories {
+		db.Github.Repositories = append(db.Github.Repositories, Repository{
+			FullName: fullName,
+			State:    StateActive,
+		})
 	}
 
-	return &db, nil
+	for _, softDeleted := range legacy.Github.SoftDeleted {
+		db.Github.Repositories = append(db.Github.Repositories, Repository{
+			FullName:  softDeleted.FullName,
+			Directory: softDeleted.Directory,
+			State:     StateSoftDeleted,
+			DeletedAt: softDeleted.DeletedAt,
+		})
+	}
+
+	return db, nil
 }
 
-func GetGitHubRepositories() ([]string, error) {
-	return getGitHubRepositories(lockfilePath)
+func writeDB(db *DB, filepath string) error {
+	db.Version = SchemaVersion
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath, data, 0644)
 }
 
-func getGitHubRepositories(filepath string) ([]string, error) {
+func GetGitHubRepositories() ([]Repository, error) {
+	return getGitHubRepositories(getLockfilePath())
+}
+
+func getGitHubRepositories(filepath string) ([]Repository, error) {
 	db, err := getDB(filepath)
 	if err != nil {
 		return nil, err
@@ -49,46 +175,44 @@ func getGitHubRepositories(filepath string) ([]string, error) {
 	return db.Github.Repositories, nil
 }
 
+// InitializeDB creates the lockfile if it is missing and rewrites files of an
+// older schema version in the current format.
 func InitializeDB() error {
-	return initializeDB(lockfilePath)
+	return initializeDB(getLockfilePath())
 }
 
 func initializeDB(filepath string) error {
 	if _, err := os.Stat(filepath); err == nil {
-		return nil
+		db, version, err := readDB(filepath)
+		if err != nil {
+			return err
+		}
+
+		if version == SchemaVersion {
+			return nil
+		}
+		return writeDB(db, filepath)
 	}
 
 	initialDB := DB{
-		Github: struct {
-			Repositories []string `json:"repositories"`
-		}{
-			Repositories: []string{},
+		Github: GitHub{
+			Repositories: []Repository{},
 		},
 	}
 
-	data, err := json.MarshalIndent(initialDB, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filepath, data, 0644)
+	return writeDB(&initialDB, filepath)
 }
 
-func UpdateGithubRepositories(repositories []string) error {
-	return updateGithubRepositories(repositories, lockfilePath)
+func UpdateGithubRepositories(repositories []Repository) error {
+	return updateGithubRepositories(repositories, getLockfilePath())
 }
 
-func updateGithubRepositories(repositories []string, filepath string) error {
+func updateGithubRepositories(repositories []Repository, filepath string) error {
 	db, err := getDB(filepath)
 	if err != nil {
 		return err
 	}
 
 	db.Github.Repositories = repositories
-	data, err := json.MarshalIndent(db, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filepath, data, 0644)
+	return writeDB(db, filepath)
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,19 +29,23 @@ func setupFile(t *testing.T, filepath string, data []byte) {
 func TestGetGitHubRepositories_Success(t *testing.T) {
 	path := "/tmp/db.json"
 	repositoriesCount := 15
-	repositories := make([]string, repositoriesCount)
+	repositories := make([]Repository, repositoriesCount)
 	for index := range repositories {
-		repositories[index] = fmt.Sprintf("repository-%d", index)
+		repositories[index] = Repository{
+			ID:       int64(index + 1),
+			FullName: fmt.Sprintf("user/repository-%d", index),
+			State:    StateActive,
+		}
 	}
 	stringifiedRepositories, err := json.Marshal(repositories)
-	jsonData := fmt.Sprintf(`{"github":{"repositories": %s}}`, stringifiedRepositories)
+	jsonData := fmt.Sprintf(`{"version": 2, "github":{"repositories": %s}}`, stringifiedRepositories)
 	setupFile(t, path, []byte(jsonData))
 
 	ghRepositories, err := getGitHubRepositories(path)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, ghRepositories)
-	assert.Equal(t, repositoriesCount, len(ghRepositories))
+	assert.Equal(t, repositories, ghRepositories)
 }
 
 func TestGetGitHubRepositories_FileNotFound(t *testing.T) {
@@ -70,7 +75,7 @@ func TestGetGitHubRepositories_EmptyFile(t *testing.T) {
 
 	assert.Nil(t, ghRepositories)
 	assert.Error(t, err)
-	assert.EqualError(t, err, "EOF")
+	assert.EqualError(t, err, "unexpected end of JSON input")
 }
 
 func TestGetGitHubRepositories_EmptyGithubObject(t *testing.T) {
@@ -83,12 +88,12 @@ func TestGetGitHubRepositories_EmptyGithubObject(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(ghRepositories))
 
-	assert.Equal(t, []string{}, ghRepositories)
+	assert.Equal(t, []Repository{}, ghRepositories)
 }
 
 func TestGetGitHubRepositories_WrongTypeRepositories(t *testing.T) {
 	path := "/tmp/wrong_type_repositories.json"
-	setupFile(t, path, []byte(`{"github":{"repositories": true}}`))
+	setupFile(t, path, []byte(`{"version": 2, "github":{"repositories": true}}`))
 
 	ghRepositories, err := getGitHubRepositories(path)
 
@@ -100,12 +105,33 @@ func TestGetGitHubRepositories_WrongTypeRepositories(t *testing.T) {
 	assert.Equal(t, "github.repositories", typeErr.Field)
 }
 
-func TestGetGitHubRepositories_TrimsWhitespaceOnRepositories(t *testing.T) {
+func TestGetGitHubRepositories_NewerVersion(t *testing.T) {
+	path := "/tmp/newer_version.json"
+	setupFile(t, path, []byte(`{"version": 99, "github":{"repositories": []}}`))
+
+	ghRepositories, err := getGitHubRepositories(path)
+
+	assert.Nil(t, ghRepositories)
+	assert.EqualError(t, err, "lockfile version 99 is newer than supported version 2")
+}
+
+func TestGetGitHubRepositories_TrimsWhitespaceOnFullNames(t *testing.T) {
 	path := "/tmp/repositories_with_whitespace.json"
+	setupFile(t, path, []byte(`{"version": 2, "github":{"repositories": [{"full_name": "  user/repo-1 ", "state": "active"}]}}`))
+
+	ghRepositories, err := getGitHubRepositories(path)
+
+	assert.NoError(t, err)
+	assert.Len(t, ghRepositories, 1)
+	assert.Equal(t, "user/repo-1", ghRepositories[0].FullName)
+}
+
+func TestGetGitHubRepositories_MigratesLegacyRepositoryNames(t *testing.T) {
+	path := "/tmp/legacy_repositories.json"
 	repositories := []string{
-		"repo-1",
-		"  repo-2  ",
-		"\trepo-3\t",
+		"user/repo-1",
+		"  user/repo-2  ",
+		"\tuser/repo-3\t",
 	}
 	stringifiedRepositories, err := json.Marshal(repositories)
 	if err != nil {
@@ -117,21 +143,52 @@ func TestGetGitHubRepositories_TrimsWhitespaceOnRepositories(t *testing.T) {
 	ghRepositories, err := getGitHubRepositories(path)
 
 	assert.NoError(t, err)
-	assert.NotNil(t, ghRepositories)
 	assert.Equal(t, len(repositories), len(ghRepositories))
 	for index := range repositories {
-		assert.Equal(t, strings.TrimSpace(repositories[index]), ghRepositories[index])
+		assert.Equal(t, strings.TrimSpace(repositories[index]), ghRepositories[index].FullName)
+		assert.Equal(t, StateActive, ghRepositories[index].State)
 	}
 }
 
+func TestGetGitHubRepositories_MigratesLegacySoftDeleted(t *testing.T) {
+	path := "/tmp/legacy_soft_deleted.json"
+	jsonData := `{"github":{"repositories": ["user/repo1"], "soft_deleted": [{"full_name": "user/gone", "directory": "gone.git", "deleted_at": "2025-01-02T03:04:05Z"}]}}`
+	setupFile(t, path, []byte(jsonData))
+
+	ghRepositories, err := getGitHubRepositories(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Repository{
+		{FullName: "user/repo1", State: StateActive},
+		{FullName: "user/gone", Directory: "gone.git", State: StateSoftDeleted, DeletedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}, ghRepositories)
+}
+
+func TestGetGitHubRepositories_LegacyWrongTypeRepositories(t *testing.T) {
+	path := "/tmp/legacy_wrong_type_repositories.json"
+	setupFile(t, path, []byte(`{"github":{"repositories": true}}`))
+
+	ghRepositories, err := getGitHubRepositories(path)
+
+	assert.Nil(t, ghRepositories)
+
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+	assert.Equal(t, "github.repositories", typeErr.Field)
+}
+
 func TestInitializeDB_DBExists(t *testing.T) {
 	path := "/tmp/db.json"
-	jsonData := `{"github":{"repositories":[]}}`
+	jsonData := `{"version": 2, "github":{"repositories":[]}}`
 	setupFile(t, path, []byte(jsonData))
 
 	err := initializeDB(path)
 
 	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, jsonData, string(data))
 }
 
 func TestInitializeDB_Success(t *testing.T) {
@@ -143,18 +200,44 @@ func TestInitializeDB_Success(t *testing.T) {
 	ghRepositories, err := getGitHubRepositories(path)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(ghRepositories))
+
+	_, version, err := readDB(path)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
 	os.Remove(path)
 }
 
+func TestInitializeDB_RewritesLegacyFile(t *testing.T) {
+	path := "/tmp/legacy_db.json"
+	setupFile(t, path, []byte(`{"github":{"repositories":["user/repo1"]}}`))
+
+	err := initializeDB(path)
+	assert.NoError(t, err)
+
+	db, version, err := readDB(path)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+	assert.Equal(t, []Repository{{FullName: "user/repo1", State: StateActive}}, db.Github.Repositories)
+}
+
+func TestInitializeDB_InvalidExistingFile(t *testing.T) {
+	path := "/tmp/invalid_existing_db.json"
+	setupFile(t, path, []byte(`{invalid json}`))
+
+	err := initializeDB(path)
+
+	assert.Error(t, err)
+}
+
 func TestUpdateGithubRepositories_EmptyRepositoriesSuccess(t *testing.T) {
 	path := "/tmp/existing_db.json"
-	jsonData := `{"github":{"repositories":[]}}`
+	jsonData := `{"version": 2, "github":{"repositories":[]}}`
 	setupFile(t, path, []byte(jsonData))
 
 	err := initializeDB(path)
 	assert.NoError(t, err)
 
-	repositories := []string{"repo"}
+	repositories := []Repository{{ID: 1, FullName: "user/repo", State: StateActive}}
 	err = updateGithubRepositories(repositories, path)
 	assert.NoError(t, err)
 
@@ -166,13 +249,34 @@ func TestUpdateGithubRepositories_EmptyRepositoriesSuccess(t *testing.T) {
 
 func TestUpdateGithubRepositories_ExistingRepositoriesSuccess(t *testing.T) {
 	path := "/tmp/existing_db.json"
-	jsonData := `{"github":{"repositories":["repo1"]}}`
+	jsonData := `{"github":{"repositories":["user/repo1"]}}`
 	setupFile(t, path, []byte(jsonData))
 
 	err := initializeDB(path)
 	assert.NoError(t, err)
 
-	repositories := []string{"repo1", "repo2"}
+	syncedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	repositories := []Repository{
+		{
+			ID:           1,
+			FullName:     "user/repo1",
+			CloneURL:     "git@github.com:user/repo1.git",
+			Directory:    "repo1.git",
+			State:        StateActive,
+			FirstSeenAt:  syncedAt,
+			LastSeenAt:   syncedAt,
+			LastSyncedAt: syncedAt,
+			Refs:         map[string]string{"refs/heads/main": "0123456789abcdef0123456789abcdef01234567"},
+		},
+		{
+			ID:           2,
+			FullName:     "user/repo2",
+			State:        StateActive,
+			LastError:    "exit status 128",
+			LastErrorAt:  syncedAt,
+			FailureCount: 3,
+		},
+	}
 	err = updateGithubRepositories(repositories, path)
 	assert.NoError(t, err)
 
@@ -181,3 +285,23 @@ func TestUpdateGithubRepositories_ExistingRepositoriesSuccess(t *testing.T) {
 
 	assert.Equal(t, repositories, db.Github.Repositories)
 }
+
+func TestUpdateGithubRepositories_OmitsEmptyFields(t *testing.T) {
+	path := "/tmp/omits_empty_fields_db.json"
+	setupFile(t, path, []byte(`{"version": 2, "github":{"repositories":[]}}`))
+
+	err := updateGithubRepositories([]Repository{{FullName: "user/repo", State: StateActive}}, path)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version": 2, "github":{"repositories":[{"full_name": "user/repo", "state": "active"}]}}`, string(data))
+}
+
+func TestGetLockfilePath(t *testing.T) {
+	t.Setenv("GITVAULT_LOCKFILE_PATH", "")
+	assert.Equal(t, lockfilePath, getLockfilePath())
+
+	t.Setenv("GITVAULT_LOCKFILE_PATH", "/backup/gitvault.lock.json")
+	assert.Equal(t, "/backup/gitvault.lock.json", getLockfilePath())
+}
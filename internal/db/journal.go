@@ -0,0 +1,115 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const journalFile = "gitvault.journal.jsonl"
+
+// Kinds of ref updates recorded in the journal.
+const (
+	RefCreated     = "created"
+	RefFastForward = "fast-forward"
+	RefForced      = "forced"
+	RefDeleted     = "deleted"
+)
+
+// RefUpdate is one ref tip change observed in a mirror. Old is empty for
+// created refs and New for deleted ones. Repository is the name at the time;
+// Source and ID identify the repository across renames.
+type RefUpdate struct {
+	Source     string    `json:"source,omitempty"`
+	ID         int64     `json:"id,omitempty"`
+	Repository string    `json:"repository"`
+	Ref        string    `json:"ref"`
+	Old        string    `json:"old,omitempty"`
+	New        string    `json:"new,omitempty"`
+	Kind       string    `json:"kind"`
+	At         time.Time `json:"at"`
+}
+
+// getJournalPath places the journal next to the lockfile. It holds one JSON
+// object per line and is only ever appended to.
+func getJournalPath() string {
+	return filepath.Join(filepath.Dir(getLockfilePath()), journalFile)
+}
+
+// AppendJournal adds updates to the end of the ref journal.
+func AppendJournal(updates []RefUpdate) error {
+	return appendJournal(updates, getJournalPath())
+}
+
+func appendJournal(updates []RefUpdate, path string) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, update := range updates {
+		if err := encoder.Encode(update); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadJournal returns the journal entries of a repository in the order they
+// were recorded, including those from before it was renamed. Entries recorded
+// without an ID, and every entry of a repository without one, are matched by
+// name instead, case-insensitively like on GitHub.
+func ReadJournal(repository Repository) ([]RefUpdate, error) {
+	return readJournal(repository, getJournalPath())
+}
+
+func (r Repository) recorded(update RefUpdate) bool {
+	if r.ID != 0 && update.ID != 0 {
+		return update.Source == r.Source && update.ID == r.ID
+	}
+	return strings.EqualFold(update.Repository, r.FullName)
+}
+
+func readJournal(repository Repository, path string) ([]RefUpdate, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var updates []RefUpdate
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var update RefUpdate
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			return nil, fmt.Errorf("journal line %d: %w", line, err)
+		}
+		if repository.recorded(update) {
+			updates = append(updates, update)
+		}
+	}
+	return updates, scanner.Err()
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendJournal_AppendsToExistingEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFile)
+	at := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	first := RefUpdate{Repository: "user/tools", Ref: "refs/heads/main", New: "aaaa", Kind: RefCreated, At: at}
+	second := RefUpdate{Repository: "user/tools", Ref: "refs/heads/main", Old: "aaaa", New: "bbbb", Kind: RefForced, At: at.Add(time.Hour)}
+
+	assert.NoError(t, appendJournal([]RefUpdate{first}, path))
+	assert.NoError(t, appendJournal([]RefUpdate{second}, path))
+
+	updates, err := readJournal(Repository{FullName: "user/tools"}, path)
+	assert.NoError(t, err)
+	assert.Equal(t, []RefUpdate{first, second}, updates)
+
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, `{"repository":"user/tools","ref":"refs/heads/main","new":"aaaa","kind":"created","at":"2026-03-01T02:00:00Z"}
+{"repository":"user/tools","ref":"refs/heads/main","old":"aaaa","new":"bbbb","kind":"forced","at":"2026-03-01T03:00:00Z"}
+`, string(data))
+}
+
+func TestAppendJournal_NothingToAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFile)
+
+	assert.NoError(t, appendJournal(nil, path))
+	assert.NoFileExists(t, path)
+}
+
+func TestReadJournal_FiltersRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFile)
+	setupFile(t, path, []byte(`{"repository":"user/tools","ref":"refs/heads/main","new":"aaaa","kind":"created"}
+
+{"repository":"acme/tools","ref":"refs/heads/main","new":"bbbb","kind":"created"}
+`))
+
+	updates, err := readJournal(Repository{FullName: "User/Tools"}, path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RefUpdate{{Repository: "user/tools", Ref: "refs/heads/main", New: "aaaa", Kind: RefCreated}}, updates)
+}
+
+func TestReadJournal_FollowsRenames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFile)
+	setupFile(t, path, []byte(`{"repository":"user/project","ref":"refs/heads/main","new":"aaaa","kind":"created"}
+{"id":1,"repository":"user/project","ref":"refs/heads/main","old":"aaaa","new":"bbbb","kind":"forced"}
+{"id":1,"repository":"user/project-old","ref":"refs/heads/main","old":"bbbb","new":"cccc","kind":"forced"}
+{"id":2,"repository":"user/project","ref":"refs/heads/main","new":"1111","kind":"created"}
+{"source":"gitlab","id":1,"repository":"gitlab/user/project-old","ref":"refs/heads/main","new":"2222","kind":"created"}
+`))
+
+	updates, err := readJournal(Repository{ID: 1, FullName: "user/project-old"}, path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RefUpdate{
+		{ID: 1, Repository: "user/project", Ref: "refs/heads/main", Old: "aaaa", New: "bbbb", Kind: RefForced},
+		{ID: 1, Repository: "user/project-old", Ref: "refs/heads/main", Old: "bbbb", New: "cccc", Kind: RefForced},
+	}, updates)
+}
+
+func TestReadJournal_FileNotFound(t *testing.T) {
+	updates, err := readJournal(Repository{FullName: "user/tools"}, filepath.Join(t.TempDir(), journalFile))
+
+	assert.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestReadJournal_InvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFile)
+	setupFile(t, path, []byte("{\"repository\":\"user/tools\"}\nnot json\n"))
+
+	_, err := readJournal(Repository{FullName: "user/tools"}, path)
+
+	assert.ErrorContains(t, err, "journal line 2")
+}
+
+func TestGetJournalPath(t *testing.T) {
+	t.Setenv("GITVAULT_LOCKFILE_PATH", "/backup/gitvault.lock.json")
+	assert.Equal(t, "/backup/gitvault.journal.jsonl", getJournalPath())
+}
@@ -0,0 +1,92 @@
+// Package forge holds what every source of repositories has in common: the
+// repository model sync consumes and the interface each forge client
+// implements.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Repository is a repository as listed by a forge. The JSON names follow the
+// GitHub API; other forges convert their own responses. Size is in
+// kilobytes.
+type Repository struct {
+	ID         int64     `json:"id"`
+	FullName   string    `json:"full_name"`
+	SSHURL     string    `json:"ssh_url"`
+	CloneURL   string    `json:"clone_url"`
+	PushedAt   time.Time `json:"pushed_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Fork       bool      `json:"fork"`
+	Archived   bool      `json:"archived"`
+	IsTemplate bool      `json:"is_template"`
+	Private    bool      `json:"private"`
+	Topics     []string  `json:"topics"`
+	Size       int64     `json:"size"`
+}
+
+// Provider lists the repositories a forge is configured to mirror.
+type Provider interface {
+	ListRepositories(ctx context.Context) ([]Repository, error)
+}
+
+// Deduplicate drops every repository whose ID was listed before, for forges
+// that list a repository once for each user, group or organization it can be
+// reached through.
+func Deduplicate(repositories []Repository) []Repository {
+	seen := make(map[int64]bool, len(repositories))
+	return slices.DeleteFunc(repositories, func(repository Repository) bool {
+		duplicate := seen[repository.ID]
+		seen[repository.ID] = true
+		return duplicate
+	})
+}
+
+// PageError reports a failure on a page after the first one, so callers can
+// tell a broken pagination walk apart from a request that failed outright.
+type PageError struct {
+	Page int
+	URL  string
+	Err  error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("failed to fetch page %d (%s): %v", e.Page, e.URL, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// NextPageURL extracts the rel="next" target from an RFC 5988 Link header.
+func NextPageURL(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		segments := strings.Split(link, ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		target := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+
+		for _, param := range segments[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(key) != "rel" {
+				continue
+			}
+
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				if rel == "next" {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package forge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNextPageURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "empty header", header: "", expected: ""},
+		{name: "only next", header: `<https://api.github.com/x?page=2>; rel="next"`, expected: "https://api.github.com/x?page=2"},
+		{name: "next among others", header: `<https://a/x?page=1>; rel="prev", <https://a/x?page=3>; rel="next", <https://a/x?page=9>; rel="last"`, expected: "https://a/x?page=3"},
+		{name: "no next", header: `<https://a/x?page=1>; rel="first", <https://a/x?page=2>; rel="prev"`, expected: ""},
+		{name: "multiple rel values", header: `<https://a/x?page=2>; rel="next last"`, expected: "https://a/x?page=2"},
+		{name: "malformed target", header: `https://a/x?page=2; rel="next"`, expected: ""},
+		{name: "missing params", header: `<https://a/x?page=2>`, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NextPageURL(tc.header); got != tc.expected {
+				t.Errorf("NextPageURL(%q) = %q, want %q", tc.header, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	repositories := []Repository{
+		{ID: 1, FullName: "acme/api"},
+		{ID: 2, FullName: "acme/web"},
+		{ID: 1, FullName: "acme/api"},
+	}
+
+	got := Deduplicate(repositories)
+
+	if want := []Repository{{ID: 1, FullName: "acme/api"}, {ID: 2, FullName: "acme/web"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Deduplicate() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPageError(t *testing.T) {
+	cause := errors.New("unexpected status code: 502")
+	err := &PageError{Page: 3, URL: "https://a/x?page=3", Err: cause}
+
+	if got, want := err.Error(), "failed to fetch page 3 (https://a/x?page=3): unexpected status code: 502"; got != want {
+		t.Fatalf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, cause) {
+		t.Fatalf("errors.Is(err, cause) = false, want true")
+	}
+}
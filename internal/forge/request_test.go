@@ -0,0 +1,84 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "application/json" {
+			t.Fatalf("Accept = %q, want application/json", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Fatalf("Authorization = %q, want token secret", got)
+		}
+		w.Header().Set("X-Total-Count", "2")
+		w.Write([]byte(`[{"id": 1}, {"id": 2}]`))
+	}))
+	defer server.Close()
+
+	var repos []Repository
+	header, err := GetJSON(context.Background(), server.Client(), server.URL, func(req *http.Request) {
+		req.Header.Set("Authorization", "token secret")
+	}, &repos)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	if want := []Repository{{ID: 1}, {ID: 2}}; !reflect.DeepEqual(repos, want) {
+		t.Fatalf("repos = %+v, want %+v", repos, want)
+	}
+	if got := header.Get("X-Total-Count"); got != "2" {
+		t.Fatalf("X-Total-Count = %q, want 2", got)
+	}
+}
+
+func TestGetJSON_PrepareReplacesAccept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Values("Accept"); !reflect.DeepEqual(got, []string{"application/vnd.github+json"}) {
+			t.Fatalf("Accept = %v, want only application/vnd.github+json", got)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	var repos []Repository
+	_, err := GetJSON(context.Background(), server.Client(), server.URL, func(req *http.Request) {
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}, &repos)
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+}
+
+func TestGetJSON_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{name: "unexpected status", status: http.StatusUnauthorized, expected: "unexpected status code: 401"},
+		{name: "invalid body", status: http.StatusOK, body: `{`, expected: "failed to decode response: unexpected EOF"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			var repos []Repository
+			_, err := GetJSON(context.Background(), server.Client(), server.URL, func(*http.Request) {}, &repos)
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("err = %v, want %s", err, tc.expected)
+			}
+		})
+	}
+}
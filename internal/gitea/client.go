@@ -0,0 +1,149 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+// limit is the page size asked for. Servers cap it at their
+// MAX_RESPONSE_ITEMS, 50 by default.
+const limit = 50
+
+// Repository is a repository as listed by the Gitea and Forgejo v1 API. Size
+// is in kilobytes.
+type Repository struct {
+	ID        int64     `json:"id"`
+	FullName  string    `json:"full_name"`
+	SSHURL    string    `json:"ssh_url"`
+	CloneURL  string    `json:"clone_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Fork      bool      `json:"fork"`
+	Archived  bool      `json:"archived"`
+	Template  bool      `json:"template"`
+	Private   bool      `json:"private"`
+	Topics    []string  `json:"topics"`
+	Size      int64     `json:"size"`
+}
+
+// repository converts a listed repository to the model sync consumes. Gitea
+// has no push timestamp; updated_at moves on every push and stands in for it.
+func (r Repository) repository() forge.Repository {
+	return forge.Repository{
+		ID:         r.ID,
+		FullName:   r.FullName,
+		SSHURL:     r.SSHURL,
+		CloneURL:   r.CloneURL,
+		PushedAt:   r.UpdatedAt,
+		UpdatedAt:  r.UpdatedAt,
+		Fork:       r.Fork,
+		Archived:   r.Archived,
+		IsTemplate: r.Template,
+		Private:    r.Private,
+		Topics:     r.Topics,
+		Size:       r.Size,
+	}
+}
+
+var _ forge.Provider = (*Client)(nil)
+
+type Client struct {
+	baseURL       string
+	token         string
+	organizations []string
+	http          *http.Client
+}
+
+func NewClient(source config.SourceConfig) *Client {
+	return &Client{
+		baseURL:       source.BaseURL + "/api/v1",
+		token:         source.Token,
+		organizations: source.Organizations,
+		http:          &http.Client{},
+	}
+}
+
+// ListRepositories lists the repositories of the token's user, when there is
+// a token, and of every configured organization. A repository listed more
+// than once is returned once.
+func (c *Client) ListRepositories(ctx context.Context) ([]forge.Repository, error) {
+	var repositories []forge.Repository
+	if c.token != "" {
+		repos, err := c.GetUserRepos(ctx)
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, repos...)
+	}
+	for _, organization := range c.organizations {
+		repos, err := c.GetOrgRepos(ctx, organization)
+		if err != nil {
+			return nil, fmt.Errorf("organization %s: %w", organization, err)
+		}
+		repositories = append(repositories, repos...)
+	}
+
+	return forge.Deduplicate(repositories), nil
+}
+
+// GetUserRepos lists every repository the token's user owns or can access
+// through collaboration and organization membership.
+func (c *Client) GetUserRepos(ctx context.Context) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, c.baseURL+"/user/repos")
+}
+
+func (c *Client) GetOrgRepos(ctx context.Context, organization string) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, fmt.Sprintf("%s/orgs/%s/repos", c.baseURL, url.PathEscape(organization)))
+}
+
+// getAllPages walks page/limit pagination until a page comes back empty or
+// the X-Total-Count header says every repository has been listed.
+func (c *Client) getAllPages(ctx context.Context, endpoint string) ([]forge.Repository, error) {
+	var repos []forge.Repository
+
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s?page=%d&limit=%d", endpoint, page, limit)
+		pageRepos, total, err := c.getPage(ctx, url)
+		if err != nil {
+			if page > 1 {
+				return nil, &forge.PageError{Page: page, URL: url, Err: err}
+			}
+			return nil, err
+		}
+
+		for _, repo := range pageRepos {
+			repos = append(repos, repo.repository())
+		}
+		if len(pageRepos) == 0 || (total >= 0 && len(repos) >= total) {
+			return repos, nil
+		}
+	}
+}
+
+// getPage fetches one page and the total count the server reported, or -1
+// when it did not report one.
+func (c *Client) getPage(ctx context.Context, url string) ([]Repository, int, error) {
+	var repos []Repository
+	header, err := forge.GetJSON(ctx, c.http, url, c.authenticate, &repos)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := strconv.Atoi(header.Get("X-Total-Count"))
+	if err != nil {
+		total = -1
+	}
+	return repos, total, nil
+}
+
+func (c *Client) authenticate(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+}
@@ -0,0 +1,255 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+func newTestClient(baseURL string, source config.SourceConfig, httpClient *http.Client) *Client {
+	source.BaseURL = baseURL
+	client := NewClient(source)
+	client.http = httpClient
+	return client
+}
+
+func TestListRepositories_UserRepos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/api/v1/user/repos" {
+			t.Fatalf("path = %s, want /api/v1/user/repos", got)
+		}
+		if got := r.URL.Query().Get("limit"); got != "50" {
+			t.Fatalf("limit = %s, want 50", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "token forgejo-token" {
+			t.Fatalf("Authorization = %q, want token forgejo-token", got)
+		}
+		w.Header().Set("X-Total-Count", "1")
+		w.Write([]byte(`[{
+			"id": 4,
+			"full_name": "alice/dotfiles",
+			"ssh_url": "ssh://git@forgejo.lan:2222/alice/dotfiles.git",
+			"clone_url": "http://forgejo.lan:3000/alice/dotfiles.git",
+			"updated_at": "2026-03-01T10:00:00Z",
+			"fork": true,
+			"archived": true,
+			"template": true,
+			"private": true,
+			"topics": ["config"],
+			"size": 120
+		}]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Token: "forgejo-token"}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	updatedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	expected := []forge.Repository{{
+		ID:         4,
+		FullName:   "alice/dotfiles",
+		SSHURL:     "ssh://git@forgejo.lan:2222/alice/dotfiles.git",
+		CloneURL:   "http://forgejo.lan:3000/alice/dotfiles.git",
+		PushedAt:   updatedAt,
+		UpdatedAt:  updatedAt,
+		Fork:       true,
+		Archived:   true,
+		IsTemplate: true,
+		Private:    true,
+		Topics:     []string{"config"},
+		Size:       120,
+	}}
+	if !reflect.DeepEqual(repos, expected) {
+		t.Fatalf("repos = %+v, want %+v", repos, expected)
+	}
+}
+
+func TestListRepositories_UserAndOrganizationRepos(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/user/repos":
+			w.Write([]byte(`[{"id": 1, "full_name": "alice/dotfiles"}, {"id": 2, "full_name": "infra/ansible"}]`))
+		case "/api/v1/orgs/infra/repos":
+			w.Write([]byte(`[{"id": 2, "full_name": "infra/ansible"}, {"id": 3, "full_name": "infra/terraform"}]`))
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Token: "forgejo-token", Organizations: []string{"infra"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.FullName)
+	}
+	if want := []string{"alice/dotfiles", "infra/ansible", "infra/terraform"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	if want := []string{"/api/v1/user/repos", "/api/v1/user/repos", "/api/v1/orgs/infra/repos", "/api/v1/orgs/infra/repos"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestListRepositories_OrganizationsWithoutToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/user/repos" {
+			t.Fatalf("listed the user's repositories without a token")
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Fatalf("Authorization = %q, want none", got)
+		}
+		w.Header().Set("X-Total-Count", "1")
+		w.Write([]byte(`[{"id": 3, "full_name": "infra/terraform"}]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Organizations: []string{"infra"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullName != "infra/terraform" {
+		t.Fatalf("repos = %+v, want infra/terraform", repos)
+	}
+}
+
+func TestListRepositories_WalksPagesUntilTotal(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		w.Header().Set("X-Total-Count", "3")
+		switch page {
+		case "1":
+			w.Write([]byte(`[{"id": 1, "full_name": "infra/one"}, {"id": 2, "full_name": "infra/two"}]`))
+		case "2":
+			w.Write([]byte(`[{"id": 3, "full_name": "infra/three"}]`))
+		default:
+			t.Fatalf("requested page %s past the total", page)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Organizations: []string{"infra"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 3 {
+		t.Fatalf("len(repos) = %d, want 3", len(repos))
+	}
+	if want := []string{"1", "2"}; !reflect.DeepEqual(pages, want) {
+		t.Fatalf("pages = %v, want %v", pages, want)
+	}
+}
+
+func TestListRepositories_WalksPagesUntilEmptyWithoutTotal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page > 2 {
+			w.Write([]byte(`[]`))
+			return
+		}
+		fmt.Fprintf(w, `[{"id": %d, "full_name": "infra/repo%d"}]`, page, page)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Organizations: []string{"infra"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("len(repos) = %d, want 2", len(repos))
+	}
+}
+
+func TestListRepositories_LaterPageFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Write([]byte(`[{"id": 1, "full_name": "infra/one"}]`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Token: "forgejo-token"}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	var pageErr *forge.PageError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("expected *forge.PageError, got %T: %v", err, err)
+	}
+	if pageErr.Page != 2 {
+		t.Fatalf("Page = %d, want 2", pageErr.Page)
+	}
+}
+
+func TestListRepositories_OrganizationNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Organizations: []string{"missing"}}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	if err == nil || err.Error() != "organization missing: unexpected status code: 404" {
+		t.Fatalf("err = %v, want organization missing: unexpected status code: 404", err)
+	}
+}
+
+func TestListRepositories_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Token: "expired"}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	if err == nil || err.Error() != "unexpected status code: 401" {
+		t.Fatalf("err = %v, want unexpected status code: 401", err)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	client := NewClient(config.SourceConfig{BaseURL: "http://forgejo.lan:3000", Token: "forgejo-token", Organizations: []string{"infra"}})
+
+	if client.baseURL != "http://forgejo.lan:3000/api/v1" {
+		t.Fatalf("baseURL = %s, want http://forgejo.lan:3000/api/v1", client.baseURL)
+	}
+	if client.token != "forgejo-token" || !reflect.DeepEqual(client.organizations, []string{"infra"}) {
+		t.Fatalf("client not configured from source: %+v", client)
+	}
+}
@@ -1,28 +1,33 @@
 package github
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
 )
 
 const (
 	defaultBaseURL = "https://api.github.com"
+	perPage        = 100
 )
 
-type Repository struct {
-	ID       int64  `json:"id"`
-	FullName string `json:"full_name"`
-	SSHURL   string `json:"ssh_url"`
-}
+// Repository is a repository as listed by the GitHub API.
+type Repository = forge.Repository
+
+// PageError reports a failure on a page after the first one.
+type PageError = forge.PageError
+
+var _ forge.Provider = (*Client)(nil)
 
 type Client struct {
 	baseURL  string
 	token    string
 	username string
+	listing  string
 	http     *http.Client
 }
 
@@ -38,37 +43,72 @@ func NewClient() *Client {
 		baseURL:  getBaseURL(),
 		token:    config.GetGitHubToken(),
 		username: config.GetGitHubUsername(),
+		listing:  config.GetGitHubListing(),
 		http:     &http.Client{},
 	}
 }
 
-func (c *Client) GetUserRepos() ([]Repository, error) {
-	var repos []Repository
+// GetRepos lists repositories according to the configured listing mode.
+func (c *Client) GetRepos(ctx context.Context) ([]Repository, error) {
+	if c.listing == config.GitHubListingAuthenticated {
+		return c.GetAuthenticatedUserRepos(ctx)
+	}
+	return c.GetUserRepos(ctx)
+}
 
-	url := fmt.Sprintf("%s/users/%s/repos", c.baseURL, c.username)
+// ListRepositories implements forge.Provider.
+func (c *Client) ListRepositories(ctx context.Context) ([]Repository, error) {
+	return c.GetRepos(ctx)
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+func (c *Client) GetUserRepos(ctx context.Context) ([]Repository, error) {
+	url := fmt.Sprintf("%s/users/%s/repos?per_page=%d", c.baseURL, c.username, perPage)
+	return c.getAllPages(ctx, url)
+}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+// GetAuthenticatedUserRepos lists every repository the token can see,
+// including private ones and those shared through collaboration or
+// organization membership.
+func (c *Client) GetAuthenticatedUserRepos(ctx context.Context) ([]Repository, error) {
+	url := fmt.Sprintf("%s/user/repos?affiliation=owner,collaborator,organization_member&visibility=all&per_page=%d", c.baseURL, perPage)
+	return c.getAllPages(ctx, url)
+}
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
-	}
-	defer resp.Body.Close()
+func (c *Client) GetOrgRepos(ctx context.Context, org string) ([]Repository, error) {
+	url := fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=%d", c.baseURL, org, perPage)
+	return c.getAllPages(ctx, url)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+func (c *Client) getAllPages(ctx context.Context, url string) ([]Repository, error) {
+	var repos []Repository
 
-	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	for page := 1; url != ""; page++ {
+		pageRepos, next, err := c.getPage(ctx, url)
+		if err != nil {
+			if page > 1 {
+				return nil, &PageError{Page: page, URL: url, Err: err}
+			}
+			return nil, err
+		}
+
+		repos = append(repos, pageRepos...)
+		url = next
 	}
 
 	return repos, nil
 }
+
+func (c *Client) getPage(ctx context.Context, url string) ([]Repository, string, error) {
+	var repos []Repository
+	header, err := forge.GetJSON(ctx, c.http, url, c.authenticate, &repos)
+	if err != nil {
+		return nil, "", err
+	}
+	return repos, forge.NextPageURL(header.Get("Link")), nil
+}
+
+func (c *Client) authenticate(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+}
@@ -1,12 +1,18 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
 )
 
 func newTestClient(baseURL, token, username string, httpClient *http.Client) *Client {
@@ -26,8 +32,8 @@ func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 
 func TestGetUserRepos_Success(t *testing.T) {
 	expected := []Repository{
-		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
-		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git", CloneURL: "https://github.com/user/repo1.git"},
+		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git", CloneURL: "https://github.com/user/repo2.git"},
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -46,7 +52,7 @@ func TestGetUserRepos_Success(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
 
-	repos, err := client.GetUserRepos()
+	repos, err := client.GetUserRepos(context.Background())
 	if err != nil {
 		t.Fatalf("got err: %v", err)
 	}
@@ -54,12 +60,65 @@ func TestGetUserRepos_Success(t *testing.T) {
 		t.Fatalf("len(repos) = %d, want %d", len(repos), len(expected))
 	}
 	for i := range repos {
-		if repos[i] != expected[i] {
+		if !reflect.DeepEqual(repos[i], expected[i]) {
 			t.Fatalf("repo[%d] = %+v, want %+v", i, repos[i], expected[i])
 		}
 	}
 }
 
+func TestGetUserRepos_DecodesTimestamps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": 1, "full_name": "user/repo1", "pushed_at": "2026-03-01T10:00:00Z", "updated_at": "2026-03-02T11:30:00Z"},
+			{"id": 2, "full_name": "user/empty", "pushed_at": null, "updated_at": "2026-03-01T09:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	repos, err := client.GetUserRepos(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC); !repos[0].PushedAt.Equal(want) {
+		t.Fatalf("repos[0].PushedAt = %v, want %v", repos[0].PushedAt, want)
+	}
+	if want := time.Date(2026, 3, 2, 11, 30, 0, 0, time.UTC); !repos[0].UpdatedAt.Equal(want) {
+		t.Fatalf("repos[0].UpdatedAt = %v, want %v", repos[0].UpdatedAt, want)
+	}
+	if !repos[1].PushedAt.IsZero() {
+		t.Fatalf("repos[1].PushedAt = %v, want zero for a repository without pushes", repos[1].PushedAt)
+	}
+}
+
+func TestGetUserRepos_DecodesFilterFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": 1, "full_name": "user/fork", "fork": true, "archived": true, "is_template": true, "private": true, "topics": ["demo", "go"], "size": 2048},
+			{"id": 2, "full_name": "user/plain"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	repos, err := client.GetUserRepos(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	expected := []Repository{
+		{ID: 1, FullName: "user/fork", Fork: true, Archived: true, IsTemplate: true, Private: true, Topics: []string{"demo", "go"}, Size: 2048},
+		{ID: 2, FullName: "user/plain"},
+	}
+	if !reflect.DeepEqual(repos, expected) {
+		t.Fatalf("repos = %+v, want %+v", repos, expected)
+	}
+}
+
 func TestGetUserRepos_DoError(t *testing.T) {
 	httpClient := &http.Client{
 		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
@@ -69,7 +128,7 @@ func TestGetUserRepos_DoError(t *testing.T) {
 
 	client := newTestClient("https://api.github.com", "test-token", "testuser", httpClient)
 
-	repos, err := client.GetUserRepos()
+	repos, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -90,7 +149,7 @@ func TestGetUserRepos_EmptyResponse(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
 
-	repos, err := client.GetUserRepos()
+	repos, err := client.GetUserRepos(context.Background())
 	if err != nil {
 		t.Fatalf("got err: %v", err)
 	}
@@ -102,7 +161,7 @@ func TestGetUserRepos_EmptyResponse(t *testing.T) {
 func TestGetUserRepos_RequestCreationError(t *testing.T) {
 	client := newTestClient("://bad-base-url", "test-token", "testuser", &http.Client{})
 
-	repos, err := client.GetUserRepos()
+	repos, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -123,7 +182,7 @@ func TestGetUserRepos_Unauthorized(t *testing.T) {
 
 	client := newTestClient(server.URL, "invalid-token", "testuser", server.Client())
 
-	_, err := client.GetUserRepos()
+	_, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -143,7 +202,7 @@ func TestGetUserRepos_Forbidden(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
 
-	_, err := client.GetUserRepos()
+	_, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -163,7 +222,7 @@ func TestGetUserRepos_NotFound(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "nonexistent-user", server.Client())
 
-	_, err := client.GetUserRepos()
+	_, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -183,7 +242,7 @@ func TestGetUserRepos_InternalServerError(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
 
-	_, err := client.GetUserRepos()
+	_, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -203,7 +262,7 @@ func TestGetUserRepos_ServiceUnavailable(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
 
-	_, err := client.GetUserRepos()
+	_, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -224,7 +283,7 @@ func TestGetUserRepos_InvalidJSON(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
 
-	_, err := client.GetUserRepos()
+	_, err := client.GetUserRepos(context.Background())
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -265,7 +324,7 @@ func TestGetUserRepos_DifferentConfigs(t *testing.T) {
 			defer server.Close()
 
 			client := newTestClient(server.URL, tc.token, tc.username, server.Client())
-			_, err := client.GetUserRepos()
+			_, err := client.GetUserRepos(context.Background())
 			if err != nil {
 				t.Fatalf("expected no error, got %v", err)
 			}
@@ -293,7 +352,7 @@ func TestGetUserRepos_LargeResponse(t *testing.T) {
 
 	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
 
-	repos, err := client.GetUserRepos()
+	repos, err := client.GetUserRepos(context.Background())
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -302,3 +361,243 @@ func TestGetUserRepos_LargeResponse(t *testing.T) {
 		t.Fatalf("expected 1000 repos, got %d", len(repos))
 	}
 }
+
+func TestGetUserRepos_RequestsMaxPageSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Fatalf("per_page = %s, want 100", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Repository{})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	if _, err := client.GetUserRepos(context.Background()); err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+}
+
+func TestGetUserRepos_FollowsLinkHeader(t *testing.T) {
+	pages := map[string][]Repository{
+		"1": {{ID: 1, FullName: "user/repo1"}, {ID: 2, FullName: "user/repo2"}},
+		"2": {{ID: 3, FullName: "user/repo3"}},
+		"3": {{ID: 4, FullName: "user/repo4"}},
+	}
+	var requested []string
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		requested = append(requested, page)
+
+		switch page {
+		case "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/users/testuser/repos?per_page=100&page=2>; rel="next", <%s/users/testuser/repos?per_page=100&page=3>; rel="last"`, server.URL, server.URL))
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/users/testuser/repos?per_page=100&page=1>; rel="prev", <%s/users/testuser/repos?per_page=100&page=3>; rel="next"`, server.URL, server.URL))
+		case "3":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/users/testuser/repos?per_page=100&page=2>; rel="prev", <%s/users/testuser/repos?per_page=100&page=1>; rel="first"`, server.URL, server.URL))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pages[page])
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	repos, err := client.GetUserRepos(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 4 {
+		t.Fatalf("len(repos) = %d, want 4", len(repos))
+	}
+	for i, repo := range repos {
+		if repo.ID != int64(i+1) {
+			t.Fatalf("repo[%d].ID = %d, want %d", i, repo.ID, i+1)
+		}
+	}
+	if strings.Join(requested, ",") != "1,2,3" {
+		t.Fatalf("requested pages = %v, want [1 2 3]", requested)
+	}
+}
+
+func TestGetUserRepos_LaterPageFailure(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/users/testuser/repos?per_page=100&page=2>; rel="next"`, server.URL))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Repository{{ID: 1, FullName: "user/repo1"}})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	repos, err := client.GetUserRepos(context.Background())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if repos != nil {
+		t.Fatalf("expected nil repos, got %#v", repos)
+	}
+
+	var pageErr *PageError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("expected *PageError, got %T: %v", err, err)
+	}
+	if pageErr.Page != 2 {
+		t.Errorf("pageErr.Page = %d, want 2", pageErr.Page)
+	}
+	if !strings.Contains(pageErr.Err.Error(), "unexpected status code: 502") {
+		t.Errorf("expected wrapped status error, got '%s'", pageErr.Err.Error())
+	}
+}
+
+func TestGetAuthenticatedUserRepos_Success(t *testing.T) {
+	expected := []Repository{
+		{ID: 1, FullName: "user/public", SSHURL: "git@github.com:user/public.git"},
+		{ID: 2, FullName: "user/private", SSHURL: "git@github.com:user/private.git"},
+		{ID: 3, FullName: "acme/shared", SSHURL: "git@github.com:acme/shared.git"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/user/repos" {
+			t.Fatalf("path = %s, want /user/repos", got)
+		}
+		query := r.URL.Query()
+		if got := query.Get("affiliation"); got != "owner,collaborator,organization_member" {
+			t.Fatalf("affiliation = %s, want owner,collaborator,organization_member", got)
+		}
+		if got := query.Get("visibility"); got != "all" {
+			t.Fatalf("visibility = %s, want all", got)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Fatalf("Authorization header not set as expected: %+v", r.Header)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(expected)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	repos, err := client.GetAuthenticatedUserRepos(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != len(expected) {
+		t.Fatalf("len(repos) = %d, want %d", len(repos), len(expected))
+	}
+	for i := range repos {
+		if !reflect.DeepEqual(repos[i], expected[i]) {
+			t.Fatalf("repo[%d] = %+v, want %+v", i, repos[i], expected[i])
+		}
+	}
+}
+
+func TestGetRepos_SelectsEndpointByListing(t *testing.T) {
+	testCases := []struct {
+		name         string
+		listing      string
+		expectedPath string
+	}{
+		{name: "default listing", listing: "", expectedPath: "/users/testuser/repos"},
+		{name: "user listing", listing: config.GitHubListingUser, expectedPath: "/users/testuser/repos"},
+		{name: "authenticated listing", listing: config.GitHubListingAuthenticated, expectedPath: "/user/repos"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tc.expectedPath {
+					t.Errorf("expected path '%s', got '%s'", tc.expectedPath, r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode([]Repository{})
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+			client.listing = tc.listing
+
+			if _, err := client.GetRepos(context.Background()); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetOrgRepos_Success(t *testing.T) {
+	expected := []Repository{
+		{ID: 1, FullName: "acme/api", SSHURL: "git@github.com:acme/api.git"},
+		{ID: 2, FullName: "acme/web", SSHURL: "git@github.com:acme/web.git"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/orgs/acme/repos" {
+			t.Fatalf("path = %s, want /orgs/acme/repos", got)
+		}
+		if got := r.URL.Query().Get("type"); got != "all" {
+			t.Fatalf("type = %s, want all", got)
+		}
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Fatalf("per_page = %s, want 100", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(expected)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	repos, err := client.GetOrgRepos(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != len(expected) {
+		t.Fatalf("len(repos) = %d, want %d", len(repos), len(expected))
+	}
+}
+
+func TestGetOrgRepos_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	_, err := client.GetOrgRepos(context.Background(), "missing-org")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if err.Error() != "unexpected status code: 404" {
+		t.Errorf("expected error 'unexpected status code: 404', got '%s'", err.Error())
+	}
+}
+
+func TestGetUserRepos_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request should not be sent with a canceled context")
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", "testuser", server.Client())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.GetUserRepos(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+const perPage = 100
+
+// listParameters ask for keyset pagination ordered by ID. Endpoints without
+// keyset support fall back to offset pagination, and both modes link the next
+// page in the Link header.
+var listParameters = fmt.Sprintf("pagination=keyset&order_by=id&sort=asc&per_page=%d&statistics=true", perPage)
+
+// Project is a project as listed by the GitLab v4 API. RepositorySize is in
+// bytes and only reported to members.
+type Project struct {
+	ID                int64     `json:"id"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	SSHURLToRepo      string    `json:"ssh_url_to_repo"`
+	HTTPURLToRepo     string    `json:"http_url_to_repo"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	ForkedFromProject *struct {
+		ID int64 `json:"id"`
+	} `json:"forked_from_project"`
+	Archived   bool     `json:"archived"`
+	Visibility string   `json:"visibility"`
+	Topics     []string `json:"topics"`
+	Statistics struct {
+		RepositorySize int64 `json:"repository_size"`
+	} `json:"statistics"`
+}
+
+// repository converts a project to the model sync consumes. GitLab has no
+// push timestamp, and last_activity_at cannot stand in for it: GitLab moves
+// it at most once an hour, so a push soon after other activity would go
+// unnoticed. PushedAt stays zero, which makes sync fetch every project on
+// every run. Internal projects count as private.
+func (p Project) repository() forge.Repository {
+	return forge.Repository{
+		ID:        p.ID,
+		FullName:  p.PathWithNamespace,
+		SSHURL:    p.SSHURLToRepo,
+		CloneURL:  p.HTTPURLToRepo,
+		UpdatedAt: p.UpdatedAt,
+		Fork:      p.ForkedFromProject != nil,
+		Archived:  p.Archived,
+		Private:   p.Visibility != "public",
+		Topics:    p.Topics,
+		Size:      p.Statistics.RepositorySize / 1024,
+	}
+}
+
+var _ forge.Provider = (*Client)(nil)
+
+type Client struct {
+	baseURL string
+	token   string
+	users   []string
+	groups  []string
+	http    *http.Client
+}
+
+func NewClient(source config.SourceConfig) *Client {
+	return &Client{
+		baseURL: source.BaseURL + "/api/v4",
+		token:   source.Token,
+		users:   source.Users,
+		groups:  source.Groups,
+		http:    &http.Client{},
+	}
+}
+
+// ListRepositories lists the projects of the configured users and groups,
+// or the projects the token is a member of when neither is configured. A
+// project listed more than once is returned once.
+func (c *Client) ListRepositories(ctx context.Context) ([]forge.Repository, error) {
+	if len(c.users) == 0 && len(c.groups) == 0 {
+		return c.GetMemberProjects(ctx)
+	}
+
+	var repositories []forge.Repository
+	for _, user := range c.users {
+		repos, err := c.GetUserProjects(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", user, err)
+		}
+		repositories = append(repositories, repos...)
+	}
+	for _, group := range c.groups {
+		repos, err := c.GetGroupProjects(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %w", group, err)
+		}
+		repositories = append(repositories, repos...)
+	}
+
+	return forge.Deduplicate(repositories), nil
+}
+
+// GetMemberProjects lists every project the token is a member of.
+func (c *Client) GetMemberProjects(ctx context.Context) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, fmt.Sprintf("%s/projects?membership=true&%s", c.baseURL, listParameters))
+}
+
+// GetUserProjects lists the projects in a user's personal namespace.
+func (c *Client) GetUserProjects(ctx context.Context, user string) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, fmt.Sprintf("%s/users/%s/projects?%s", c.baseURL, url.PathEscape(user), listParameters))
+}
+
+// GetGroupProjects lists the projects of a group and its subgroups. group is
+// the full path, such as "platform/tools".
+func (c *Client) GetGroupProjects(ctx context.Context, group string) ([]forge.Repository, error) {
+	return c.getAllPages(ctx, fmt.Sprintf("%s/groups/%s/projects?include_subgroups=true&%s", c.baseURL, url.PathEscape(group), listParameters))
+}
+
+func (c *Client) getAllPages(ctx context.Context, url string) ([]forge.Repository, error) {
+	var repos []forge.Repository
+
+	for page := 1; url != ""; page++ {
+		projects, next, err := c.getPage(ctx, url)
+		if err != nil {
+			if page > 1 {
+				return nil, &forge.PageError{Page: page, URL: url, Err: err}
+			}
+			return nil, err
+		}
+
+		for _, project := range projects {
+			repos = append(repos, project.repository())
+		}
+		url = next
+	}
+
+	return repos, nil
+}
+
+func (c *Client) getPage(ctx context.Context, url string) ([]Project, string, error) {
+	var projects []Project
+	header, err := forge.GetJSON(ctx, c.http, url, c.authenticate, &projects)
+	if err != nil {
+		return nil, "", err
+	}
+	return projects, forge.NextPageURL(header.Get("Link")), nil
+}
+
+func (c *Client) authenticate(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+}
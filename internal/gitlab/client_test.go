@@ -0,0 +1,228 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+func newTestClient(baseURL string, source config.SourceConfig, httpClient *http.Client) *Client {
+	source.BaseURL = baseURL
+	client := NewClient(source)
+	client.http = httpClient
+	return client
+}
+
+func TestListRepositories_MemberProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Path; got != "/api/v4/projects" {
+			t.Fatalf("path = %s, want /api/v4/projects", got)
+		}
+		query := r.URL.Query()
+		if query.Get("membership") != "true" || query.Get("pagination") != "keyset" || query.Get("order_by") != "id" || query.Get("per_page") != "100" {
+			t.Fatalf("query not set as expected: %s", r.URL.RawQuery)
+		}
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "glpat-token" {
+			t.Fatalf("PRIVATE-TOKEN = %q, want glpat-token", got)
+		}
+		w.Write([]byte(`[{
+			"id": 7,
+			"path_with_namespace": "platform/tools/cli",
+			"ssh_url_to_repo": "git@gitlab.example.com:platform/tools/cli.git",
+			"http_url_to_repo": "https://gitlab.example.com/platform/tools/cli.git",
+			"updated_at": "2026-03-02T11:30:00Z",
+			"forked_from_project": {"id": 3},
+			"archived": true,
+			"visibility": "internal",
+			"topics": ["go"],
+			"statistics": {"repository_size": 2097152}
+		}]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Token: "glpat-token"}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	expected := []forge.Repository{{
+		ID:        7,
+		FullName:  "platform/tools/cli",
+		SSHURL:    "git@gitlab.example.com:platform/tools/cli.git",
+		CloneURL:  "https://gitlab.example.com/platform/tools/cli.git",
+		UpdatedAt: time.Date(2026, 3, 2, 11, 30, 0, 0, time.UTC),
+		Fork:      true,
+		Archived:  true,
+		Private:   true,
+		Topics:    []string{"go"},
+		Size:      2048,
+	}}
+	if !reflect.DeepEqual(repos, expected) {
+		t.Fatalf("repos = %+v, want %+v", repos, expected)
+	}
+}
+
+func TestListRepositories_UsersAndGroups(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		switch r.URL.EscapedPath() {
+		case "/api/v4/users/alice/projects":
+			w.Write([]byte(`[{"id": 1, "path_with_namespace": "alice/dotfiles", "visibility": "public"}]`))
+		case "/api/v4/groups/platform%2Ftools/projects":
+			if got := r.URL.Query().Get("include_subgroups"); got != "true" {
+				t.Fatalf("include_subgroups = %q, want true", got)
+			}
+			w.Write([]byte(`[{"id": 2, "path_with_namespace": "platform/tools/cli", "visibility": "private"}, {"id": 1, "path_with_namespace": "alice/dotfiles", "visibility": "public"}]`))
+		default:
+			t.Fatalf("unexpected path %s", r.URL.EscapedPath())
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Users: []string{"alice"}, Groups: []string{"platform/tools"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	expected := []forge.Repository{
+		{ID: 1, FullName: "alice/dotfiles"},
+		{ID: 2, FullName: "platform/tools/cli", Private: true},
+	}
+	if !reflect.DeepEqual(repos, expected) {
+		t.Fatalf("repos = %+v, want %+v", repos, expected)
+	}
+	if want := []string{"/api/v4/users/alice/projects", "/api/v4/groups/platform%2Ftools/projects"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestListRepositories_OmitsTokenWhenUnset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header["Private-Token"]; ok {
+			t.Fatalf("PRIVATE-TOKEN sent without a token")
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Users: []string{"alice"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+	if len(repos) != 0 {
+		t.Fatalf("len(repos) = %d, want 0", len(repos))
+	}
+}
+
+func TestListRepositories_FollowsKeysetLinks(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("id_after") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/groups/platform/projects?id_after=1&pagination=keyset>; rel="next"`, server.URL))
+			w.Write([]byte(`[{"id": 1, "path_with_namespace": "platform/one"}]`))
+		case "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/groups/platform/projects?id_after=2&pagination=keyset>; rel="next"`, server.URL))
+			w.Write([]byte(`[{"id": 2, "path_with_namespace": "platform/two"}]`))
+		case "2":
+			w.Write([]byte(`[{"id": 3, "path_with_namespace": "platform/three"}]`))
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Groups: []string{"platform"}}, server.Client())
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("got err: %v", err)
+	}
+
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.FullName)
+	}
+	if want := []string{"platform/one", "platform/two", "platform/three"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+}
+
+func TestListRepositories_LaterPageFailure(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id_after") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/projects?id_after=1>; rel="next"`, server.URL))
+			w.Write([]byte(`[{"id": 1, "path_with_namespace": "platform/one"}]`))
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Token: "glpat-token"}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	var pageErr *forge.PageError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("expected *forge.PageError, got %T: %v", err, err)
+	}
+	if pageErr.Page != 2 {
+		t.Fatalf("Page = %d, want 2", pageErr.Page)
+	}
+}
+
+func TestListRepositories_GroupNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Groups: []string{"missing"}}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	if err == nil || err.Error() != "group missing: unexpected status code: 404" {
+		t.Fatalf("err = %v, want group missing: unexpected status code: 404", err)
+	}
+}
+
+func TestListRepositories_InvalidJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message": "not a list"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.SourceConfig{Token: "glpat-token"}, server.Client())
+
+	_, err := client.ListRepositories(context.Background())
+
+	if err == nil {
+		t.Fatal("expected a decode error")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	client := NewClient(config.SourceConfig{BaseURL: "https://gitlab.example.com", Token: "glpat-token", Groups: []string{"platform"}})
+
+	if client.baseURL != "https://gitlab.example.com/api/v4" {
+		t.Fatalf("baseURL = %s, want https://gitlab.example.com/api/v4", client.baseURL)
+	}
+	if client.token != "glpat-token" || !reflect.DeepEqual(client.groups, []string{"platform"}) {
+		t.Fatalf("client not configured from source: %+v", client)
+	}
+}
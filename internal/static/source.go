@@ -0,0 +1,140 @@
+// Package static lists the git remotes of a static source: repositories
+// that are on no forge API, given by URL in the configuration or in a file.
+package static
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+var _ forge.Provider = (*Source)(nil)
+
+type Source struct {
+	repositories []config.StaticRepository
+	file         string
+}
+
+func NewSource(source config.SourceConfig) *Source {
+	return &Source{
+		repositories: source.Repositories,
+		file:         source.File,
+	}
+}
+
+// ListRepositories lists the configured repositories followed by the URLs in
+// the file, which is read on every listing so it can change between runs. A
+// remote listed twice under the same name is returned once.
+func (s *Source) ListRepositories(ctx context.Context) ([]forge.Repository, error) {
+	listed := s.repositories
+	if s.file != "" {
+		urls, err := readURLs(s.file)
+		if err != nil {
+			return nil, err
+		}
+		for _, url := range urls {
+			listed = append(listed, config.StaticRepository{URL: url})
+		}
+	}
+
+	var repositories []forge.Repository
+	urls := make(map[string]string, len(listed))
+	for _, entry := range listed {
+		if err := checkCredentials(entry.URL); err != nil {
+			return nil, err
+		}
+
+		name := entry.Name
+		if name == "" {
+			name = nameFromURL(entry.URL)
+		}
+		if name == "" || !filepath.IsLocal(name) || strings.Contains(name, `\`) || path.Clean(name) != name {
+			return nil, fmt.Errorf("repository %s: name %q must be a relative path", entry.URL, name)
+		}
+
+		key := strings.ToLower(name)
+		if url, ok := urls[key]; ok {
+			if url == entry.URL {
+				continue
+			}
+			return nil, fmt.Errorf("repository %s: name %q is used by %s", entry.URL, name, url)
+		}
+		urls[key] = entry.URL
+
+		repositories = append(repositories, repository(name, entry.URL))
+	}
+	return repositories, nil
+}
+
+// repository converts a remote to the model sync consumes. The ID is derived
+// from the name, the only identity a remote has, and the URL serves every
+// clone protocol. Remotes report no push time, so they are fetched on every
+// run.
+func repository(name, url string) forge.Repository {
+	id := fnv.New64a()
+	id.Write([]byte(strings.ToLower(name)))
+
+	return forge.Repository{
+		ID:       int64(id.Sum64() >> 1),
+		FullName: name,
+		SSHURL:   url,
+		CloneURL: url,
+	}
+}
+
+// readURLs reads a file of remote URLs, one per line. Blank lines and lines
+// starting with # are skipped.
+func readURLs(file string) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var urls []string
+	for line := range strings.Lines(string(content)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, nil
+}
+
+// checkCredentials rejects a remote URL that carries credentials, which
+// would be written to the lockfile and the logs. A user name alone is fine
+// over ssh, where it is not a secret.
+func checkCredentials(remote string) error {
+	parsed, err := url.Parse(remote)
+	if err != nil || !strings.Contains(remote, "://") || parsed.User == nil {
+		return nil
+	}
+	if _, hasPassword := parsed.User.Password(); hasPassword || parsed.Scheme == "http" || parsed.Scheme == "https" {
+		parsed.User = nil
+		return fmt.Errorf("repository %s: credentials in the URL are not supported, authenticate over SSH instead", parsed)
+	}
+	return nil
+}
+
+// nameFromURL derives a name from the path of a remote, without the .git
+// suffix: https://github.com/torvalds/linux.git and
+// git@github.com:torvalds/linux.git both become torvalds/linux.
+func nameFromURL(remote string) string {
+	remotePath := remote
+	if parsed, err := url.Parse(remote); err == nil && strings.Contains(remote, "://") {
+		remotePath = parsed.Path
+	} else if host, scpPath, found := strings.Cut(remote, ":"); found && !strings.Contains(host, "/") {
+		remotePath = scpPath
+	}
+
+	remotePath = strings.TrimSuffix(strings.Trim(remotePath, "/"), ".git")
+	return strings.Trim(remotePath, "/")
+}
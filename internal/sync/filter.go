@@ -0,0 +1,119 @@
+package sync
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+// repositoryFilter decides which listed repositories are mirrored. Patterns
+// and topics are lower case.
+type repositoryFilter struct {
+	include          []string
+	exclude          []string
+	excludeForks     bool
+	excludeArchived  bool
+	excludeTemplates bool
+	excludePrivate   bool
+	excludePublic    bool
+	includeTopics    []string
+	excludeTopics    []string
+	maxSizeKB        int64
+}
+
+func newRepositoryFilter(filters config.FilterConfig) repositoryFilter {
+	return repositoryFilter{
+		include:          filters.Include,
+		exclude:          filters.Exclude,
+		excludeForks:     filters.ExcludeForks,
+		excludeArchived:  filters.ExcludeArchived,
+		excludeTemplates: filters.ExcludeTemplates,
+		excludePrivate:   filters.ExcludePrivate,
+		excludePublic:    filters.ExcludePublic,
+		includeTopics:    filters.IncludeTopics,
+		excludeTopics:    filters.ExcludeTopics,
+		maxSizeKB:        filters.MaxSizeKB,
+	}
+}
+
+// rule names the first filter that skips repository, in the words of the
+// configuration, or returns "" when the repository is mirrored.
+func (f repositoryFilter) rule(repository forge.Repository) string {
+	fullName := strings.ToLower(repository.FullName)
+	if len(f.include) > 0 && !slices.ContainsFunc(f.include, func(pattern string) bool { return matchesPattern(pattern, fullName) }) {
+		return "include"
+	}
+	for _, pattern := range f.exclude {
+		if matchesPattern(pattern, fullName) {
+			return fmt.Sprintf("exclude %q", pattern)
+		}
+	}
+
+	switch {
+	case f.excludeForks && repository.Fork:
+		return "exclude_forks"
+	case f.excludeArchived && repository.Archived:
+		return "exclude_archived"
+	case f.excludeTemplates && repository.IsTemplate:
+		return "exclude_templates"
+	case f.excludePrivate && repository.Private:
+		return "exclude_private"
+	case f.excludePublic && !repository.Private:
+		return "exclude_public"
+	}
+
+	topics := make([]string, 0, len(repository.Topics))
+	for _, topic := range repository.Topics {
+		topics = append(topics, strings.ToLower(topic))
+	}
+	if len(f.includeTopics) > 0 && !slices.ContainsFunc(f.includeTopics, func(topic string) bool { return slices.Contains(topics, topic) }) {
+		return "include_topics"
+	}
+	for _, topic := range f.excludeTopics {
+		if slices.Contains(topics, topic) {
+			return fmt.Sprintf("exclude_topics %q", topic)
+		}
+	}
+
+	if f.maxSizeKB > 0 && repository.Size > f.maxSizeKB {
+		return "max_size_mb"
+	}
+	return ""
+}
+
+// matchesPattern matches a glob against a full name. Patterns were validated
+// when the configuration was loaded.
+func matchesPattern(pattern, fullName string) bool {
+	matched, _ := path.Match(pattern, fullName)
+	return matched
+}
+
+// filteredTarget is a listed repository that a filter rule skipped.
+type filteredTarget struct {
+	target mirrorTarget
+	rule   string
+}
+
+// filterTargets splits targets into the ones to mirror and the ones a filter
+// rule skips.
+func filterTargets(targets []mirrorTarget, filter repositoryFilter) ([]mirrorTarget, []filteredTarget) {
+	var mirrored []mirrorTarget
+	var filtered []filteredTarget
+	for _, target := range targets {
+		if rule := filter.rule(target.repository); rule != "" {
+			filtered = append(filtered, filteredTarget{target: target, rule: rule})
+			continue
+		}
+		mirrored = append(mirrored, target)
+	}
+	return mirrored, filtered
+}
+
+// skipReason is how a filtered repository is reported.
+func skipReason(rule string) string {
+	return "skipped by rule " + rule
+}
@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryFilter_Rule(t *testing.T) {
+	tests := []struct {
+		name       string
+		filters    config.FilterConfig
+		repository forge.Repository
+		expected   string
+	}{
+		{name: "no filters", repository: forge.Repository{FullName: "user/tools", Fork: true}, expected: ""},
+		{name: "included", filters: config.FilterConfig{Include: []string{"acme/*"}}, repository: forge.Repository{FullName: "Acme/Tools"}, expected: ""},
+		{name: "not included", filters: config.FilterConfig{Include: []string{"acme/*"}}, repository: forge.Repository{FullName: "user/tools"}, expected: "include"},
+		{name: "glob does not cross owner", filters: config.FilterConfig{Include: []string{"*"}}, repository: forge.Repository{FullName: "user/tools"}, expected: "include"},
+		{name: "excluded", filters: config.FilterConfig{Exclude: []string{"*/dataset-*"}}, repository: forge.Repository{FullName: "acme/dataset-images"}, expected: `exclude "*/dataset-*"`},
+		{name: "fork", filters: config.FilterConfig{ExcludeForks: true}, repository: forge.Repository{FullName: "user/tools", Fork: true}, expected: "exclude_forks"},
+		{name: "archived", filters: config.FilterConfig{ExcludeArchived: true}, repository: forge.Repository{FullName: "user/tools", Archived: true}, expected: "exclude_archived"},
+		{name: "template", filters: config.FilterConfig{ExcludeTemplates: true}, repository: forge.Repository{FullName: "user/tools", IsTemplate: true}, expected: "exclude_templates"},
+		{name: "private", filters: config.FilterConfig{ExcludePrivate: true}, repository: forge.Repository{FullName: "user/tools", Private: true}, expected: "exclude_private"},
+		{name: "public", filters: config.FilterConfig{ExcludePublic: true}, repository: forge.Repository{FullName: "user/tools"}, expected: "exclude_public"},
+		{name: "public kept when private excluded", filters: config.FilterConfig{ExcludePrivate: true}, repository: forge.Repository{FullName: "user/tools"}, expected: ""},
+		{name: "has included topic", filters: config.FilterConfig{IncludeTopics: []string{"backup"}}, repository: forge.Repository{FullName: "user/tools", Topics: []string{"go", "Backup"}}, expected: ""},
+		{name: "lacks included topic", filters: config.FilterConfig{IncludeTopics: []string{"backup"}}, repository: forge.Repository{FullName: "user/tools", Topics: []string{"go"}}, expected: "include_topics"},
+		{name: "excluded topic", filters: config.FilterConfig{ExcludeTopics: []string{"demo"}}, repository: forge.Repository{FullName: "user/tools", Topics: []string{"demo"}}, expected: `exclude_topics "demo"`},
+		{name: "too large", filters: config.FilterConfig{MaxSizeKB: 1024}, repository: forge.Repository{FullName: "user/tools", Size: 1025}, expected: "max_size_mb"},
+		{name: "at size limit", filters: config.FilterConfig{MaxSizeKB: 1024}, repository: forge.Repository{FullName: "user/tools", Size: 1024}, expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, newRepositoryFilter(tc.filters).rule(tc.repository))
+		})
+	}
+}
+
+func TestRun_ReportsFilteredRepositories(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git"},
+		{ID: 2, FullName: "user/fork", SSHURL: "git@github.com:user/fork.git", Fork: true},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	s := testSettings()
+	s.filter = newRepositoryFilter(config.FilterConfig{ExcludeForks: true})
+	result, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, 1)
+	assert.Equal(t, filepath.Join(dir, "tools.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, RepositoryResult{
+		FullName:  "user/fork",
+		Directory: filepath.Join(dir, "fork.git"),
+		Outcome:   OutcomeFiltered,
+		Reason:    "skipped by rule exclude_forks",
+	}, result.Repositories[1])
+	stored, err := db.GetGitHubRepositories()
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+	assert.Equal(t, "user/tools", stored[0].FullName)
+}
+
+func TestRun_DoesNotSoftDeleteFilteredRepositories(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "fork.git"), 0755)
+	repos := []forge.Repository{
+		{ID: 2, FullName: "user/fork", SSHURL: "git@github.com:user/fork.git", Fork: true},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{{ID: 2, FullName: "user/fork", Directory: "fork.git", State: db.StateActive}})
+
+	s := testSettings()
+	s.filter = newRepositoryFilter(config.FilterConfig{ExcludeForks: true})
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Empty(t, ops.updateCalls)
+	assert.Equal(t, db.StateActive, storedRepository(t, "user/fork").State)
+	assert.DirExists(t, filepath.Join(dir, "fork.git"))
+}
+
+func TestPlan_ReportsFilteredRepositories(t *testing.T) {
+	repos := []forge.Repository{
+		{ID: 1, FullName: "acme/dataset-images", SSHURL: "git@github.com:acme/dataset-images.git"},
+	}
+	setupMocks(t, repos, nil, newMockGitOps())
+
+	s := testSettings()
+	s.filter = newRepositoryFilter(config.FilterConfig{Exclude: []string{"*/dataset-*"}})
+	result, err := plan(context.Background(), t.TempDir(), s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, actionSkipFiltered, result.Repositories[0].Action)
+	assert.Equal(t, `skipped by rule exclude "*/dataset-*"`, result.Repositories[0].Detail)
+}
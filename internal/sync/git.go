@@ -0,0 +1,244 @@
+package sync
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenEnvironment is the variable the credential helper reads the token
+// from. Passing it through the environment keeps it out of the process list
+// and out of the mirror's config.
+const tokenEnvironment = "GITVAULT_GIT_TOKEN"
+
+// usernameEnvironment is the variable the credential helper reads the
+// username from.
+const usernameEnvironment = "GITVAULT_GIT_USERNAME"
+
+// defaultUsername is the username sent with a token when the forge does not
+// need a particular one.
+const defaultUsername = "x-access-token"
+
+// credentialHelper answers git's credential requests with the username and
+// token. The empty helper before it disables helpers configured on the host.
+const credentialHelper = `!f() { test "$1" = get && printf 'username=%s\npassword=%s\n' "$` + usernameEnvironment + `" "$` + tokenEnvironment + `"; }; f`
+
+// gitAuth holds what git needs to authenticate against the remote. username
+// is who the token belongs to, for forges that check it.
+type gitAuth struct {
+	username   string
+	token      string
+	sshCommand string
+}
+
+// gitWaitDelay bounds how long a git process may keep its output open after
+// its context is done, through a child such as ssh that outlived it.
+const gitWaitDelay = 5 * time.Second
+
+// newGitCommand builds a git command that is stopped together with its
+// children when ctx is done. Killing git alone leaves a hung ssh or
+// git-remote-https holding its output open, and Wait blocking on it.
+func newGitCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	killProcessGroup(cmd)
+	cmd.WaitDelay = gitWaitDelay
+	return cmd
+}
+
+// gitCommand builds a git command that authenticates with auth and never
+// prompts for credentials.
+func gitCommand(ctx context.Context, auth gitAuth, args ...string) *exec.Cmd {
+	if auth.token != "" {
+		args = append([]string{"-c", "credential.helper=", "-c", "credential.helper=" + credentialHelper}, args...)
+	}
+
+	cmd := newGitCommand(ctx, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if auth.token != "" {
+		cmd.Env = append(cmd.Env, usernameEnvironment+"="+cmp.Or(auth.username, defaultUsername), tokenEnvironment+"="+auth.token)
+	}
+	if auth.sshCommand != "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND="+auth.sshCommand)
+	}
+	return cmd
+}
+
+func gitCloneMirror(ctx context.Context, url, targetDirectory string, auth gitAuth, output io.Writer) error {
+	cmd := gitCommand(ctx, auth, "clone", "--mirror", url, targetDirectory)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// mirrorRefspec fetches every ref of the remote into the mirror.
+// excludeGitvault keeps GitVault's own refs out of every fetch, so pruning
+// never deletes them.
+const (
+	mirrorRefspec   = "+refs/*:refs/*"
+	excludeGitvault = "^" + gitvaultNamespace + "*"
+)
+
+// gitRemoteUpdate fetches a mirror with automatic maintenance disabled, so
+// objects that a force push made unreachable survive until their old tips
+// have been preserved. Refs deleted upstream are pruned, whatever the host's
+// fetch.prune says, so their deletion is seen and their tips preserved.
+func gitRemoteUpdate(ctx context.Context, repository string, auth gitAuth, output io.Writer) error {
+	cmd := gitCommand(ctx, auth, "-c", "gc.auto=0", "-c", "maintenance.auto=false", "fetch", "--prune", "origin", mirrorRefspec, excludeGitvault)
+	cmd.Dir = repository
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// gitVerifyMirror checks that a freshly cloned directory is a usable bare
+// repository.
+func gitVerifyMirror(ctx context.Context, repository string) error {
+	cmd := newGitCommand(ctx, "rev-parse", "--is-bare-repository")
+	cmd.Dir = repository
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(output)) != "true" {
+		return fmt.Errorf("%s is not a bare repository", repository)
+	}
+	return nil
+}
+
+// gitFetchRefspecs fetches refspecs the mirror's own +refs/*:refs/* does not
+// cover, without making them part of the mirror's configuration.
+func gitFetchRefspecs(ctx context.Context, repository string, refspecs []string, auth gitAuth, output io.Writer) error {
+	args := append([]string{"-c", "gc.auto=0", "-c", "maintenance.auto=false", "fetch", "origin"}, refspecs...)
+	args = append(args, excludeGitvault)
+	cmd := gitCommand(ctx, auth, args...)
+	cmd.Dir = repository
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// gitLFSFetch downloads every Git LFS object referenced by any ref of the
+// mirror. It needs git-lfs to be installed.
+func gitLFSFetch(ctx context.Context, repository string, auth gitAuth, output io.Writer) error {
+	cmd := gitCommand(ctx, auth, "lfs", "fetch", "--all", "origin")
+	cmd.Dir = repository
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// gitListRefs returns the object every ref of a mirror points to, leaving
+// out the refs GitVault created itself.
+func gitListRefs(ctx context.Context, repository string) (map[string]string, error) {
+	cmd := newGitCommand(ctx, "for-each-ref", "--format=%(objectname) %(refname)")
+	cmd.Dir = repository
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		objectName, refName, found := strings.Cut(line, " ")
+		if found && !strings.HasPrefix(refName, gitvaultNamespace) {
+			refs[refName] = objectName
+		}
+	}
+	return refs, nil
+}
+
+// gitIsAncestor reports whether ancestor is reachable from descendant, that
+// is whether moving a ref between them was a fast-forward.
+func gitIsAncestor(ctx context.Context, repository, ancestor, descendant string) (bool, error) {
+	cmd := newGitCommand(ctx, "merge-base", "--is-ancestor", ancestor, descendant)
+	cmd.Dir = repository
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func gitUpdateRef(ctx context.Context, repository, ref, object string) error {
+	cmd := newGitCommand(ctx, "update-ref", ref, object)
+	cmd.Dir = repository
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func gitRemoteURL(repository string) (string, error) {
+	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	cmd.Dir = repository
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func gitSetRemoteURL(repository, url string, output io.Writer) error {
+	cmd := exec.Command("git", "remote", "set-url", "origin", url)
+	cmd.Dir = repository
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// logWriter turns the output of a git process into one log record per line,
+// so the output of concurrent processes stays attributable to its repository.
+type logWriter struct {
+	logger *slog.Logger
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func newLogWriter(logger *slog.Logger) *logWriter {
+	return &logWriter{logger: logger}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buffer.Write(p)
+	for {
+		line, err := w.buffer.ReadString('\n')
+		if err != nil {
+			w.buffer.WriteString(line)
+			break
+		}
+		w.log(line)
+	}
+	return len(p), nil
+}
+
+// Flush logs a trailing line that was not terminated by a newline.
+func (w *logWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buffer.Len() > 0 {
+		w.log(w.buffer.String())
+		w.buffer.Reset()
+	}
+}
+
+func (w *logWriter) log(line string) {
+	if line = strings.TrimRight(line, "\r\n"); line != "" {
+		w.logger.Info("git", "output", line)
+	}
+}
@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func logLines(t *testing.T, buffer *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogWriter_LogsEveryLineWithRepository(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil)).With("repository", "user/repo1")
+
+	writer := newLogWriter(logger)
+	writer.Write([]byte("Cloning into bare repository...\nremote: Enumer"))
+	writer.Write([]byte("ating objects: 5, done.\r\n\n"))
+	writer.Write([]byte("trailing without newline"))
+	writer.Flush()
+
+	entries := logLines(t, &buffer)
+	assert.Len(t, entries, 3)
+	for _, entry := range entries {
+		assert.Equal(t, "user/repo1", entry["repository"])
+		assert.Equal(t, "git", entry["msg"])
+	}
+	assert.Equal(t, "Cloning into bare repository...", entries[0]["output"])
+	assert.Equal(t, "remote: Enumerating objects: 5, done.", entries[1]["output"])
+	assert.Equal(t, "trailing without newline", entries[2]["output"])
+}
+
+func TestLogWriter_FlushWithoutPendingOutput(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+
+	writer := newLogWriter(logger)
+	writer.Write([]byte("done\n"))
+	writer.Flush()
+	writer.Flush()
+
+	assert.Len(t, logLines(t, &buffer), 1)
+}
+
+func TestGitCommand_KeepsTokenOutOfArguments(t *testing.T) {
+	cmd := gitCommand(context.Background(), gitAuth{token: "secret-token"}, "remote", "update")
+
+	assert.NotContains(t, strings.Join(cmd.Args, " "), "secret-token")
+	assert.Equal(t, []string{"remote", "update"}, cmd.Args[len(cmd.Args)-2:])
+	assert.Contains(t, cmd.Env, tokenEnvironment+"=secret-token")
+	assert.Contains(t, cmd.Env, "GIT_TERMINAL_PROMPT=0")
+}
+
+func TestGitCommand_WithoutToken(t *testing.T) {
+	cmd := gitCommand(context.Background(), gitAuth{}, "remote", "update")
+
+	assert.Equal(t, []string{"git", "remote", "update"}, cmd.Args)
+	assert.NotContains(t, strings.Join(cmd.Env, "\n"), tokenEnvironment)
+	assert.NotContains(t, strings.Join(cmd.Env, "\n"), usernameEnvironment)
+}
+
+func TestGitCommand_SetsSSHCommand(t *testing.T) {
+	cmd := gitCommand(context.Background(), gitAuth{sshCommand: "ssh -o StrictHostKeyChecking=yes"}, "remote", "update")
+
+	assert.Equal(t, []string{"git", "remote", "update"}, cmd.Args)
+	assert.Contains(t, cmd.Env, "GIT_SSH_COMMAND=ssh -o StrictHostKeyChecking=yes")
+}
+
+func TestGitCommand_CredentialHelperAnswersWithToken(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	cmd := gitCommand(context.Background(), gitAuth{token: "secret-token"}, "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=github.com\n\n")
+	output, err := cmd.Output()
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(output), "username=x-access-token\npassword=secret-token\n")
+}
+
+func TestGitCommand_CredentialHelperAnswersWithUsername(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	cmd := gitCommand(context.Background(), gitAuth{username: "alice", token: "app-password"}, "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=bitbucket.org\n\n")
+	output, err := cmd.Output()
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(output), "username=alice\npassword=app-password\n")
+}
+
+func TestGitSetRemoteURL_WritesOutputToRepositoryLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	var output bytes.Buffer
+	err := gitSetRemoteURL(t.TempDir(), "git@github.com:user/repo.git", &output)
+
+	assert.Error(t, err)
+	assert.Contains(t, output.String(), "not a git repository")
+}
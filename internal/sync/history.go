@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+)
+
+// matchesRef reports whether ref is the ref the user asked for, which may be
+// given in full or as a branch or tag name such as "main".
+func matchesRef(ref, query string) bool {
+	for _, prefix := range []string{"", "refs/", "refs/heads/", "refs/tags/"} {
+		if ref == prefix+query {
+			return true
+		}
+	}
+	return false
+}
+
+// journalRepository resolves a repository name to its lockfile record, whose
+// source and ID also find the entries journaled under earlier names. An
+// active record wins over a soft-deleted one of the same name, and a name the
+// lockfile does not know is looked up as it is.
+func journalRepository(repository string) (db.Repository, error) {
+	inv, err := readInventory()
+	if err != nil {
+		return db.Repository{}, err
+	}
+
+	found := db.Repository{FullName: repository}
+	for _, record := range inv.repositories {
+		if strings.EqualFold(record.FullName, repository) && (found.State == "" || record.State == db.StateActive) {
+			found = record
+		}
+	}
+	return found, nil
+}
+
+// History writes the journaled ref changes of a repository, oldest first,
+// optionally limited to a single ref.
+func History(w io.Writer, repository, ref string) error {
+	record, err := journalRepository(repository)
+	if err != nil {
+		return err
+	}
+
+	updates, err := db.ReadJournal(record)
+	if err != nil {
+		return fmt.Errorf("failed to read ref journal: %w", err)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TIME\tREF\tOLD\tNEW\tKIND")
+	for _, update := range updates {
+		if ref != "" && !matchesRef(update.Ref, ref) {
+			continue
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", update.At.UTC().Format(time.RFC3339), update.Ref, orDash(update.Old), orDash(update.New), update.Kind)
+	}
+	return table.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesRef(t *testing.T) {
+	assert.True(t, matchesRef("refs/heads/main", "main"))
+	assert.True(t, matchesRef("refs/heads/main", "heads/main"))
+	assert.True(t, matchesRef("refs/heads/main", "refs/heads/main"))
+	assert.True(t, matchesRef("refs/tags/v1", "v1"))
+	assert.False(t, matchesRef("refs/heads/feature/main", "main"))
+}
+
+func TestHistory(t *testing.T) {
+	setupMocks(t, nil, nil, newMockGitOps())
+	at := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	assert.NoError(t, db.AppendJournal([]db.RefUpdate{
+		{Repository: "user/tools", Ref: "refs/heads/main", New: "aaaa", Kind: db.RefCreated, At: at},
+		{Repository: "user/tools", Ref: "refs/heads/feature", New: "cccc", Kind: db.RefCreated, At: at},
+		{Repository: "acme/tools", Ref: "refs/heads/main", New: "dddd", Kind: db.RefCreated, At: at},
+		{Repository: "user/tools", Ref: "refs/heads/main", Old: "aaaa", New: "bbbb", Kind: db.RefForced, At: at.Add(24 * time.Hour)},
+	}))
+
+	var output bytes.Buffer
+	err := History(&output, "user/tools", "main")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `TIME                  REF              OLD   NEW   KIND
+2026-03-01T02:00:00Z  refs/heads/main  -     aaaa  created
+2026-03-02T02:00:00Z  refs/heads/main  aaaa  bbbb  forced
+`, output.String())
+}
+
+func TestHistory_IncludesEntriesFromBeforeRename(t *testing.T) {
+	setupMocks(t, nil, nil, newMockGitOps())
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/project-old", State: db.StateActive},
+		{ID: 2, FullName: "user/project", State: db.StateActive},
+	})
+	at := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	assert.NoError(t, db.AppendJournal([]db.RefUpdate{
+		{ID: 1, Repository: "user/project", Ref: "refs/heads/main", New: "aaaa", Kind: db.RefCreated, At: at},
+		{ID: 2, Repository: "user/project", Ref: "refs/heads/main", New: "1111", Kind: db.RefCreated, At: at.Add(24 * time.Hour)},
+		{ID: 1, Repository: "user/project-old", Ref: "refs/heads/main", Old: "aaaa", New: "bbbb", Kind: db.RefForced, At: at.Add(24 * time.Hour)},
+	}))
+
+	var output bytes.Buffer
+	err := History(&output, "User/Project-Old", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `TIME                  REF              OLD   NEW   KIND
+2026-03-01T02:00:00Z  refs/heads/main  -     aaaa  created
+2026-03-02T02:00:00Z  refs/heads/main  aaaa  bbbb  forced
+`, output.String())
+}
+
+func TestHistory_AllRefs(t *testing.T) {
+	setupMocks(t, nil, nil, newMockGitOps())
+	assert.NoError(t, db.AppendJournal([]db.RefUpdate{
+		{Repository: "user/tools", Ref: "refs/heads/main", New: "aaaa", Kind: db.RefCreated},
+		{Repository: "user/tools", Ref: "refs/heads/feature", New: "cccc", Kind: db.RefCreated},
+	}))
+
+	var output bytes.Buffer
+	err := History(&output, "user/tools", "")
+
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), "refs/heads/main")
+	assert.Contains(t, output.String(), "refs/heads/feature")
+}
+
+func TestRun_JournalsRefUpdates(t *testing.T) {
+	at := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	mockNow(t, at)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.git")
+	os.MkdirAll(existing, 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/existing", SSHURL: "git@github.com:user/existing.git"},
+		{ID: 2, FullName: "user/new", SSHURL: "git@github.com:user/new.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.refs[existing] = map[string]string{"refs/heads/main": "aaaa", "refs/heads/old": "cccc"}
+	ops.refsAfterUpdate[existing] = map[string]string{"refs/heads/main": "bbbb"}
+	ops.fastForwards["aaaa..bbbb"] = true
+	ops.refs[filepath.Join(dir, "new.git")] = map[string]string{"refs/heads/main": "1111"}
+	setupMocks(t, repos, nil, ops)
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	existingUpdates, err := db.ReadJournal(db.Repository{FullName: "user/existing"})
+	assert.NoError(t, err)
+	assert.Equal(t, []db.RefUpdate{
+		{ID: 1, Repository: "user/existing", Ref: "refs/heads/main", Old: "aaaa", New: "bbbb", Kind: db.RefFastForward, At: at},
+		{ID: 1, Repository: "user/existing", Ref: "refs/heads/old", Old: "cccc", Kind: db.RefDeleted, At: at},
+	}, existingUpdates)
+
+	newUpdates, err := db.ReadJournal(db.Repository{FullName: "user/new"})
+	assert.NoError(t, err)
+	assert.Equal(t, []db.RefUpdate{
+		{ID: 2, Repository: "user/new", Ref: "refs/heads/main", New: "1111", Kind: db.RefCreated, At: at},
+	}, newUpdates)
+}
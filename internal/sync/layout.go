@@ -0,0 +1,182 @@
+package sync
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+var (
+	remoteURLFn    = gitRemoteURL
+	setRemoteURLFn = gitSetRemoteURL
+)
+
+func repositoryOwner(fullName string) string {
+	owner, _, found := strings.Cut(fullName, "/")
+	if !found {
+		return ""
+	}
+	return owner
+}
+
+// repositoryDirectory resolves where a repository is mirrored for the given
+// layout. organization is set when the repository was listed through a
+// configured organization, which the flat layout turns into a subdirectory.
+func repositoryDirectory(dir, layout, organization string, repository forge.Repository) string {
+	name := repositoryName(repository.FullName)
+
+	switch layout {
+	case "", config.LayoutFlat:
+		return filepath.Join(dir, organization, name+".git")
+	case config.LayoutOwner:
+		return filepath.Join(dir, repositoryOwner(repository.FullName), name+".git")
+	}
+
+	return filepath.Join(dir, strings.NewReplacer(
+		"{owner}", repositoryOwner(repository.FullName),
+		"{name}", name,
+		"{full_name}", repository.FullName,
+		"{id}", strconv.FormatInt(repository.ID, 10),
+	).Replace(layout))
+}
+
+// legacyDirectory is where releases without layouts put every mirror.
+func legacyDirectory(dir string, repository forge.Repository) string {
+	return filepath.Join(dir, repositoryName(repository.FullName)+".git")
+}
+
+// cloneURL is the remote of a repository for the configured clone protocol.
+func cloneURL(protocol string, repository forge.Repository) string {
+	if protocol == config.CloneProtocolHTTPS {
+		return repository.CloneURL
+	}
+	return repository.SSHURL
+}
+
+// isRemoteOf reports whether url is a remote of repository over any protocol.
+func isRemoteOf(url string, repository forge.Repository) bool {
+	return url != "" && (url == repository.SSHURL || url == repository.CloneURL)
+}
+
+// mirrorTargets resolves the directory and remote of every repository. A
+// repository listed both for the user and for an organization is only
+// mirrored once, as an organization repository. Repositories of other
+// sources follow, keyed by source name.
+func mirrorTargets(dir string, settings settings, userRepos []forge.Repository, orgRepos map[string][]forge.Repository, sourceRepos map[string][]forge.Repository) []mirrorTarget {
+	organizations := make([]string, 0, len(orgRepos))
+	for organization := range orgRepos {
+		organizations = append(organizations, organization)
+	}
+	slices.Sort(organizations)
+
+	var orgTargets []mirrorTarget
+	inOrganization := make(map[int64]bool)
+	for _, organization := range organizations {
+		for _, repository := range orgRepos[organization] {
+			inOrganization[repository.ID] = true
+			options := settings.forRepository(repository.FullName)
+			orgTargets = append(orgTargets, mirrorTarget{
+				repository: repository,
+				directory:  repositoryDirectory(dir, settings.layout, organization, repository),
+				url:        cloneURL(options.cloneProtocol, repository),
+				options:    options,
+			})
+		}
+	}
+
+	var targets []mirrorTarget
+	for _, repository := range userRepos {
+		if inOrganization[repository.ID] {
+			continue
+		}
+		options := settings.forRepository(repository.FullName)
+		targets = append(targets, mirrorTarget{
+			repository: repository,
+			directory:  repositoryDirectory(dir, settings.layout, "", repository),
+			url:        cloneURL(options.cloneProtocol, repository),
+			options:    options,
+		})
+	}
+
+	targets = append(targets, orgTargets...)
+	return append(targets, sourceTargets(dir, settings, sourceRepos)...)
+}
+
+// sourceTargets places the repositories of every configured source under a
+// directory named after the source, at their full name on that forge.
+// layout only applies to GitHub: forges nest repositories in groups,
+// workspaces and projects, and dropping any of them would put same-named
+// repositories in one directory. Their full name is prefixed with the source
+// name, which keeps them apart from GitHub repositories in the lockfile,
+// filters and reports.
+func sourceTargets(dir string, settings settings, sourceRepos map[string][]forge.Repository) []mirrorTarget {
+	var targets []mirrorTarget
+	for _, source := range settings.sources {
+		for _, listed := range sourceRepos[source.Name] {
+			repository := listed
+			repository.FullName = source.Name + "/" + listed.FullName
+			options := settings.forRepository(repository.FullName)
+			options.source = true
+			options.token = source.Token
+			options.username = source.Username
+			targets = append(targets, mirrorTarget{
+				repository: repository,
+				source:     source.Name,
+				directory:  filepath.Join(dir, source.Name, filepath.FromSlash(listed.FullName)+".git"),
+				url:        cloneURL(options.cloneProtocol, repository),
+				options:    options,
+			})
+		}
+	}
+	return targets
+}
+
+// migrateLegacyMirrors moves mirrors left at their flat location into the
+// configured layout. A mirror is only moved when its origin points at the
+// repository being placed, so a same-named repository of another owner is
+// never claimed by mistake.
+func migrateLegacyMirrors(dir string, targets []mirrorTarget) {
+	for _, target := range targets {
+		legacy := legacyDirectory(dir, target.repository)
+		if target.source != "" || legacy == target.directory {
+			continue
+		}
+
+		if _, err := os.Stat(target.directory); err == nil {
+			continue
+		}
+
+		if info, err := os.Stat(legacy); err != nil || !info.IsDir() {
+			continue
+		}
+
+		remoteURL, err := remoteURLFn(legacy)
+		if err != nil {
+			slog.Warn("could not read remote of legacy mirror", "repository", target.repository.FullName, "dir", legacy, "error", err)
+			continue
+		}
+		if !isRemoteOf(remoteURL, target.repository) {
+			continue
+		}
+
+		if err := moveMirror(legacy, target.directory); err != nil {
+			slog.Error("failed to migrate mirror", "repository", target.repository.FullName, "from", legacy, "to", target.directory, "error", err)
+			continue
+		}
+		slog.Info("migrated mirror", "repository", target.repository.FullName, "from", legacy, "to", target.directory)
+	}
+}
+
+func moveMirror(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(to), err)
+	}
+	return os.Rename(from, to)
+}
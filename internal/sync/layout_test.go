@@ -0,0 +1,198 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryDirectory(t *testing.T) {
+	repository := forge.Repository{ID: 42, FullName: "alice/tools"}
+
+	tests := []struct {
+		name         string
+		layout       string
+		organization string
+		expected     string
+	}{
+		{name: "flat user repository", layout: config.LayoutFlat, expected: "/backup/tools.git"},
+		{name: "flat organization repository", layout: config.LayoutFlat, organization: "acme", expected: "/backup/acme/tools.git"},
+		{name: "empty layout is flat", layout: "", expected: "/backup/tools.git"},
+		{name: "owner layout", layout: config.LayoutOwner, expected: "/backup/alice/tools.git"},
+		{name: "owner layout ignores organization", layout: config.LayoutOwner, organization: "acme", expected: "/backup/alice/tools.git"},
+		{name: "template", layout: "github/{owner}/{name}.git", expected: "/backup/github/alice/tools.git"},
+		{name: "full name template", layout: "{full_name}.git", expected: "/backup/alice/tools.git"},
+		{name: "id template", layout: "ids/{id}.git", expected: "/backup/ids/42.git"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, repositoryDirectory("/backup", tc.layout, tc.organization, repository))
+		})
+	}
+}
+
+func TestSourceTargets(t *testing.T) {
+	sourceRepos := map[string][]forge.Repository{
+		"gitlab": {{ID: 7, FullName: "platform/tools/cli", SSHURL: "git@gitlab.com:platform/tools/cli.git"}},
+		"unused": {{ID: 8, FullName: "platform/other"}},
+	}
+
+	s := testSettings()
+	s.layout = config.LayoutOwner
+	s.cloneProtocol = config.CloneProtocolSSH
+	s.sources = []config.SourceConfig{{Name: "gitlab", Type: config.SourceTypeGitLab, Token: "glpat-token"}}
+	targets := sourceTargets("/backup", s, sourceRepos)
+
+	assert.Equal(t, []mirrorTarget{{
+		repository: forge.Repository{ID: 7, FullName: "gitlab/platform/tools/cli", SSHURL: "git@gitlab.com:platform/tools/cli.git"},
+		source:     "gitlab",
+		directory:  "/backup/gitlab/platform/tools/cli.git",
+		url:        "git@gitlab.com:platform/tools/cli.git",
+		options:    repositoryOptions{cloneProtocol: config.CloneProtocolSSH, source: true, token: "glpat-token"},
+	}}, targets)
+}
+
+func TestSourceTargets_FlatLayoutKeepsNamespace(t *testing.T) {
+	sourceRepos := map[string][]forge.Repository{
+		"work": {
+			{ID: 1, FullName: "platform/api"},
+			{ID: 2, FullName: "infra/api"},
+		},
+	}
+
+	s := testSettings()
+	s.sources = []config.SourceConfig{{Name: "work", Type: config.SourceTypeGitLab, Token: "glpat-token"}}
+	targets := sourceTargets("/backup", s, sourceRepos)
+
+	assert.Equal(t, "/backup/work/platform/api.git", targets[0].directory)
+	assert.Equal(t, "/backup/work/infra/api.git", targets[1].directory)
+}
+
+func TestSourceTargets_SourceWithoutTokenGetsNoGitHubToken(t *testing.T) {
+	sourceRepos := map[string][]forge.Repository{
+		"gitlab": {{ID: 7, FullName: "platform/cli", CloneURL: "https://gitlab.com/platform/cli.git"}},
+	}
+
+	s := testSettings()
+	s.cloneProtocol = config.CloneProtocolHTTPS
+	s.sources = []config.SourceConfig{{Name: "gitlab", Type: config.SourceTypeGitLab, Groups: []string{"platform"}}}
+	targets := sourceTargets("/backup", s, sourceRepos)
+
+	assert.Equal(t, repositoryOptions{cloneProtocol: config.CloneProtocolHTTPS, source: true}, targets[0].options)
+	assert.Equal(t, gitAuth{}, targets[0].options.auth(gitAuth{token: "github-token"}))
+}
+
+func TestRun_OwnerLayoutSeparatesSameNamedRepos(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "alice/tools", SSHURL: "git@github.com:alice/tools.git"},
+		{ID: 2, FullName: "acme/tools", SSHURL: "git@github.com:acme/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	_, err := run(context.Background(), dir, settings{layout: config.LayoutOwner})
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, 2)
+	assert.Equal(t, filepath.Join(dir, "alice", "tools.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, filepath.Join(dir, "acme", "tools.git"), ops.cloneCalls[1].targetDirectory)
+}
+
+func TestRun_MigratesLegacyMirrorWithMatchingRemote(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "tools.git")
+	os.MkdirAll(legacy, 0755)
+	os.WriteFile(filepath.Join(legacy, "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "acme/tools", SSHURL: "git@github.com:acme/tools.git"},
+		{ID: 2, FullName: "alice/tools", SSHURL: "git@github.com:alice/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.remoteURLs[legacy] = "git@github.com:alice/tools.git"
+	setupMocks(t, repos, nil, ops)
+
+	_, err := run(context.Background(), dir, settings{layout: config.LayoutOwner})
+
+	assert.NoError(t, err)
+	migrated := filepath.Join(dir, "alice", "tools.git")
+	assert.FileExists(t, filepath.Join(migrated, "HEAD"))
+	assert.NoDirExists(t, legacy)
+
+	assert.Len(t, ops.cloneCalls, 1)
+	assert.Equal(t, filepath.Join(dir, "acme", "tools.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, []string{migrated}, ops.updateCalls)
+}
+
+func TestRun_MigratesLegacyMirrorWithRemoteOfOtherProtocol(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "tools.git")
+	os.MkdirAll(legacy, 0755)
+
+	repos := []forge.Repository{
+		{ID: 2, FullName: "alice/tools", SSHURL: "git@github.com:alice/tools.git", CloneURL: "https://github.com/alice/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.remoteURLs[legacy] = "https://github.com/alice/tools.git"
+	setupMocks(t, repos, nil, ops)
+
+	_, err := run(context.Background(), dir, settings{layout: config.LayoutOwner})
+
+	assert.NoError(t, err)
+	assert.DirExists(t, filepath.Join(dir, "alice", "tools.git"))
+	assert.Empty(t, ops.cloneCalls)
+}
+
+func TestRun_DoesNotMigrateLegacyMirrorWithOtherRemote(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "tools.git")
+	os.MkdirAll(legacy, 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "acme/tools", SSHURL: "git@github.com:acme/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.remoteURLs[legacy] = "git@github.com:alice/tools.git"
+	setupMocks(t, repos, nil, ops)
+
+	_, err := run(context.Background(), dir, settings{layout: config.LayoutOwner})
+
+	assert.NoError(t, err)
+	assert.DirExists(t, legacy)
+	assert.Len(t, ops.cloneCalls, 1)
+	assert.Equal(t, filepath.Join(dir, "acme", "tools.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Empty(t, ops.updateCalls)
+}
+
+func TestRun_DoesNotMigrateOverExistingMirror(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "tools.git")
+	target := filepath.Join(dir, "alice", "tools.git")
+	os.MkdirAll(legacy, 0755)
+	os.MkdirAll(target, 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "alice/tools", SSHURL: "git@github.com:alice/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.remoteURLs[legacy] = "git@github.com:alice/tools.git"
+	setupMocks(t, repos, nil, ops)
+
+	_, err := run(context.Background(), dir, settings{layout: config.LayoutOwner})
+
+	assert.NoError(t, err)
+	assert.DirExists(t, legacy)
+	assert.Equal(t, []string{target}, ops.updateCalls)
+}
@@ -0,0 +1,316 @@
+package sync
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+)
+
+var now = time.Now
+
+// inventory is the lockfile's view of every repository GitVault has seen,
+// indexed by source and forge ID, and by full name.
+type inventory struct {
+	repositories []db.Repository
+	byID         map[sourceID]int
+	byName       map[string]int
+}
+
+// sourceID identifies a repository across renames. IDs are only unique
+// within the forge that assigned them.
+type sourceID struct {
+	source string
+	id     int64
+}
+
+// forgeName is how messages refer to where the repositories of a source are
+// listed.
+func forgeName(source string) string {
+	return cmp.Or(source, "GitHub")
+}
+
+func loadInventory() (*inventory, error) {
+	if err := db.InitializeDB(); err != nil {
+		return nil, fmt.Errorf("failed to initialize lockfile: %w", err)
+	}
+
+	repositories, err := db.GetGitHubRepositories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repositories from lockfile: %w", err)
+	}
+
+	inv := &inventory{repositories: repositories}
+	inv.reindex()
+	return inv, nil
+}
+
+// readInventory loads the lockfile without creating or migrating it, for
+// callers that must not write state. A missing lockfile is an empty inventory.
+func readInventory() (*inventory, error) {
+	repositories, err := db.GetGitHubRepositories()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read repositories from lockfile: %w", err)
+	}
+
+	inv := &inventory{repositories: repositories}
+	inv.reindex()
+	return inv, nil
+}
+
+func (inv *inventory) reindex() {
+	inv.byID = make(map[sourceID]int, len(inv.repositories))
+	inv.byName = make(map[string]int, len(inv.repositories))
+	for index, repository := range inv.repositories {
+		if repository.ID != 0 {
+			inv.byID[sourceID{repository.Source, repository.ID}] = index
+		}
+		inv.byName[repository.FullName] = index
+	}
+}
+
+func (inv *inventory) save() error {
+	if err := db.UpdateGithubRepositories(inv.repositories); err != nil {
+		return fmt.Errorf("failed to write repositories to lockfile: %w", err)
+	}
+	return nil
+}
+
+func (inv *inventory) get(fullName string) *db.Repository {
+	index, ok := inv.byName[fullName]
+	if !ok {
+		return nil
+	}
+	return &inv.repositories[index]
+}
+
+// find looks a repository listed on source up by ID, falling back to its
+// full name for records migrated from lockfiles that did not store IDs. A
+// record with another ID is another repository, even under the same name:
+// one created where a renamed repository used to be.
+func (inv *inventory) find(source string, repository forge.Repository) *db.Repository {
+	if index, ok := inv.byID[sourceID{source, repository.ID}]; ok {
+		return &inv.repositories[index]
+	}
+	if record := inv.get(repository.FullName); record != nil && record.ID == 0 && record.Source == source {
+		return record
+	}
+	return nil
+}
+
+func (inv *inventory) add(repository db.Repository) *db.Repository {
+	inv.repositories = append(inv.repositories, repository)
+	inv.reindex()
+	return &inv.repositories[len(inv.repositories)-1]
+}
+
+func (inv *inventory) remove(fullName string) {
+	index, ok := inv.byName[fullName]
+	if !ok {
+		return
+	}
+
+	inv.repositories = slices.Delete(inv.repositories, index, index+1)
+	inv.reindex()
+}
+
+// knownDirectory resolves the mirror directory of a repository migrated from
+// a lockfile that only stored its full name.
+func knownDirectory(dir string, settings settings, fullName string) string {
+	organization := ""
+	if owner := repositoryOwner(fullName); slices.Contains(settings.organizations, owner) {
+		organization = owner
+	}
+	return repositoryDirectory(dir, settings.layout, organization, forge.Repository{FullName: fullName})
+}
+
+// reconcile compares the listed repositories with the ones recorded by
+// previous runs. Repositories that disappeared are soft-deleted: their mirror
+// stays on disk but is no longer updated. Soft-deleted repositories
+// that are listed again are restored. Records of filtered repositories are
+// left as they are, since the repositories still exist.
+func (inv *inventory) reconcile(dir string, settings settings, targets []mirrorTarget, filtered []filteredTarget) error {
+	seenAt := now().UTC()
+	listed := make(map[string]bool, len(targets))
+	for _, skipped := range filtered {
+		if record := inv.find(skipped.target.source, skipped.target.repository); record != nil {
+			listed[record.FullName] = true
+		}
+	}
+
+	for _, target := range targets {
+		repository := target.repository
+		listed[repository.FullName] = true
+
+		directory, err := filepath.Rel(dir, target.directory)
+		if err != nil {
+			return fmt.Errorf("failed to resolve mirror of %s: %w", repository.FullName, err)
+		}
+
+		record := inv.find(target.source, repository)
+		if record == nil {
+			record = inv.add(db.Repository{
+				Source:      target.source,
+				FullName:    repository.FullName,
+				State:       db.StateActive,
+				FirstSeenAt: seenAt,
+			})
+		}
+
+		if record.FullName != repository.FullName {
+			renameMirror(dir, record, target)
+			record.FullName = repository.FullName
+		}
+
+		if record.State == db.StateSoftDeleted {
+			slog.Info("soft-deleted repository reappeared on "+forgeName(target.source), "repository", repository.FullName, "deleted_at", record.DeletedAt)
+			record.State = db.StateActive
+			record.DeletedAt = time.Time{}
+		}
+
+		record.ID = repository.ID
+		record.CloneURL = target.url
+		record.Directory = directory
+		record.LastSeenAt = seenAt
+		inv.reindex()
+	}
+
+	for index := range inv.repositories {
+		record := &inv.repositories[index]
+		if listed[record.FullName] || record.State != db.StateActive {
+			continue
+		}
+
+		if record.Directory == "" {
+			directory, err := filepath.Rel(dir, knownDirectory(dir, settings, record.FullName))
+			if err != nil {
+				return fmt.Errorf("failed to resolve mirror of %s: %w", record.FullName, err)
+			}
+			record.Directory = directory
+		}
+
+		slog.Info("repository disappeared from "+forgeName(record.Source)+", soft-deleting", "repository", record.FullName, "dir", record.Directory)
+		record.State = db.StateSoftDeleted
+		record.DeletedAt = seenAt
+	}
+
+	return nil
+}
+
+func (inv *inventory) recordSuccess(fullName string, refs map[string]string) {
+	record := inv.get(fullName)
+	if record == nil {
+		return
+	}
+
+	record.LastSyncedAt = now().UTC()
+	record.LastError = ""
+	record.LastErrorAt = time.Time{}
+	record.FailureCount = 0
+	if refs != nil {
+		record.Refs = refs
+	}
+}
+
+// recordFetch remembers the forge timestamps a mirror was fetched at.
+func (inv *inventory) recordFetch(repository forge.Repository) {
+	record := inv.get(repository.FullName)
+	if record == nil {
+		return
+	}
+
+	record.LastFetchedAt = now().UTC()
+	record.PushedAt = repository.PushedAt
+	record.UpdatedAt = repository.UpdatedAt
+}
+
+// skipFetch explains why an existing mirror does not need a fetch, or
+// returns "" when it does. Sync and the dry run both decide through it.
+func (inv *inventory) skipFetch(target mirrorTarget, settings settings) string {
+	switch {
+	case inv.upToDate(target.repository, settings):
+		return "nothing pushed since " + inv.get(target.repository.FullName).PushedAt.UTC().Format(time.RFC3339)
+	case inv.fetchedWithin(target, settings):
+		return fmt.Sprintf("fetched at %s, within fetch_interval %s", inv.get(target.repository.FullName).LastFetchedAt.UTC().Format(time.RFC3339), target.options.fetchInterval)
+	}
+	return ""
+}
+
+// upToDate reports whether a repository can skip its fetch: nothing was
+// pushed since the last successful fetch, which happened within the full
+// refresh interval. Repositories whose last sync failed are always fetched.
+func (inv *inventory) upToDate(repository forge.Repository, settings settings) bool {
+	record := inv.get(repository.FullName)
+	if settings.force || record == nil || record.FailureCount > 0 {
+		return false
+	}
+	if record.PushedAt.IsZero() || repository.PushedAt.IsZero() || repository.PushedAt.After(record.PushedAt) {
+		return false
+	}
+	return now().Sub(record.LastFetchedAt) < settings.fullRefresh
+}
+
+// fetchedWithin reports whether a mirror was fetched more recently than the
+// fetch interval configured for it, which caps how often it is fetched.
+func (inv *inventory) fetchedWithin(target mirrorTarget, settings settings) bool {
+	record := inv.get(target.repository.FullName)
+	if settings.force || record == nil || record.FailureCount > 0 || target.options.fetchInterval <= 0 {
+		return false
+	}
+	return now().Sub(record.LastFetchedAt) < target.options.fetchInterval
+}
+
+func (inv *inventory) recordFailure(fullName string, err error) {
+	record := inv.get(fullName)
+	if record == nil {
+		return
+	}
+
+	record.LastError = err.Error()
+	record.LastErrorAt = now().UTC()
+	record.FailureCount++
+}
+
+// renameMirror moves the mirror of a repository that was renamed or
+// transferred on its forge to its new location and points it at the new remote,
+// so it is updated in place instead of being cloned again.
+func renameMirror(dir string, record *db.Repository, target mirrorTarget) {
+	repository := target.repository
+	previous := filepath.Join(dir, record.Directory)
+	slog.Info("repository renamed on "+forgeName(target.source), "repository", repository.FullName, "previous", record.FullName, "id", repository.ID)
+
+	if record.Directory == "" {
+		return
+	}
+
+	if info, err := os.Stat(previous); err != nil || !info.IsDir() {
+		slog.Warn("mirror of renamed repository not found", "repository", repository.FullName, "dir", previous)
+		return
+	}
+
+	if previous != target.directory {
+		if _, err := os.Stat(target.directory); err == nil {
+			slog.Error("cannot move renamed mirror, destination already exists", "repository", repository.FullName, "from", previous, "to", target.directory)
+			return
+		}
+
+		if err := moveMirror(previous, target.directory); err != nil {
+			slog.Error("failed to move renamed mirror", "repository", repository.FullName, "from", previous, "to", target.directory, "error", err)
+			return
+		}
+		slog.Info("moved renamed mirror", "repository", repository.FullName, "from", previous, "to", target.directory)
+	}
+
+	output := newLogWriter(slog.With("repository", repository.FullName))
+	defer output.Flush()
+	if err := setRemoteURLFn(target.directory, target.url, output); err != nil {
+		slog.Error("failed to update remote of renamed mirror", "repository", repository.FullName, "dir", target.directory, "error", err)
+	}
+}
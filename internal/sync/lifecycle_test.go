@@ -0,0 +1,579 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockNow(t *testing.T, at time.Time) {
+	t.Helper()
+
+	originalNow := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = originalNow })
+}
+
+func seedInventory(t *testing.T, repositories []db.Repository) {
+	t.Helper()
+
+	assert.NoError(t, db.InitializeDB())
+	assert.NoError(t, db.UpdateGithubRepositories(repositories))
+}
+
+func storedRepository(t *testing.T, fullName string) db.Repository {
+	t.Helper()
+
+	repositories, err := db.GetGitHubRepositories()
+	assert.NoError(t, err)
+	for _, repository := range repositories {
+		if repository.FullName == fullName {
+			return repository
+		}
+	}
+	t.Fatalf("repository %s not found in lockfile", fullName)
+	return db.Repository{}
+}
+
+func TestRun_RecordsListedRepositories(t *testing.T) {
+	seenAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pushedAt := time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC)
+	mockNow(t, seenAt)
+	dir := t.TempDir()
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git", PushedAt: pushedAt},
+		{ID: 2, FullName: "acme/repo2", SSHURL: "git@github.com:acme/repo2.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.refs[filepath.Join(dir, "repo1.git")] = map[string]string{"refs/heads/main": "aaaa"}
+	setupMocks(t, repos[:1], nil, ops)
+	mockOrganizations(t, map[string][]forge.Repository{"acme": repos[1:]}, nil)
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	repositories, err := db.GetGitHubRepositories()
+	assert.NoError(t, err)
+	assert.Equal(t, []db.Repository{
+		{
+			ID:            1,
+			FullName:      "user/repo1",
+			CloneURL:      "git@github.com:user/repo1.git",
+			Directory:     "repo1.git",
+			State:         db.StateActive,
+			FirstSeenAt:   seenAt,
+			LastSeenAt:    seenAt,
+			LastSyncedAt:  seenAt,
+			LastFetchedAt: seenAt,
+			PushedAt:      pushedAt,
+			Refs:          map[string]string{"refs/heads/main": "aaaa"},
+		},
+		{
+			ID:            2,
+			FullName:      "acme/repo2",
+			CloneURL:      "git@github.com:acme/repo2.git",
+			Directory:     filepath.Join("acme", "repo2.git"),
+			State:         db.StateActive,
+			FirstSeenAt:   seenAt,
+			LastSeenAt:    seenAt,
+			LastSyncedAt:  seenAt,
+			LastFetchedAt: seenAt,
+		},
+	}, repositories)
+}
+
+func TestRun_RecordsFailures(t *testing.T) {
+	dir := t.TempDir()
+	failedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockNow(t, failedAt)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.cloneErr = errors.New("exit status 128")
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/repo1", State: db.StateActive, FailureCount: 2},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+
+	record := storedRepository(t, "user/repo1")
+	assert.Equal(t, "exit status 128", record.LastError)
+	assert.Equal(t, failedAt, record.LastErrorAt)
+	assert.Equal(t, 3, record.FailureCount)
+	assert.True(t, record.LastSyncedAt.IsZero())
+}
+
+func TestRun_SuccessClearsFailures(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "repo1.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/repo1", State: db.StateActive, LastError: "boom", LastErrorAt: time.Now(), FailureCount: 4},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	record := storedRepository(t, "user/repo1")
+	assert.Empty(t, record.LastError)
+	assert.True(t, record.LastErrorAt.IsZero())
+	assert.Zero(t, record.FailureCount)
+	assert.False(t, record.LastSyncedAt.IsZero())
+}
+
+func TestRun_SoftDeletesDisappearedRepository(t *testing.T) {
+	dir := t.TempDir()
+	deletedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockNow(t, deletedAt)
+	os.MkdirAll(filepath.Join(dir, "repo1.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "gone.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/repo1", Directory: "repo1.git", State: db.StateActive},
+		{ID: 2, FullName: "user/gone", Directory: "gone.git", State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "repo1.git")}, ops.updateCalls)
+	assert.DirExists(t, filepath.Join(dir, "gone.git"))
+
+	record := storedRepository(t, "user/gone")
+	assert.Equal(t, db.StateSoftDeleted, record.State)
+	assert.Equal(t, deletedAt, record.DeletedAt)
+	assert.Equal(t, "gone.git", record.Directory)
+}
+
+func TestRun_SoftDeletesMigratedRepositoryWithoutDirectory(t *testing.T) {
+	ops := newMockGitOps()
+	setupMocks(t, []forge.Repository{}, nil, ops)
+	seedInventory(t, []db.Repository{
+		{FullName: "acme/gone", State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), t.TempDir(), settings{layout: config.LayoutFlat, organizations: []string{"acme"}})
+	assert.NoError(t, err)
+
+	record := storedRepository(t, "acme/gone")
+	assert.Equal(t, db.StateSoftDeleted, record.State)
+	assert.Equal(t, filepath.Join("acme", "gone.git"), record.Directory)
+}
+
+func TestRun_KeepsSoftDeletedRepositoryUntouched(t *testing.T) {
+	dir := t.TempDir()
+	deletedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockNow(t, deletedAt.Add(24*time.Hour))
+
+	ops := newMockGitOps()
+	setupMocks(t, []forge.Repository{}, nil, ops)
+	seedInventory(t, []db.Repository{
+		{FullName: "user/gone", Directory: "gone.git", State: db.StateSoftDeleted, DeletedAt: deletedAt},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	assert.Empty(t, ops.cloneCalls)
+	assert.Empty(t, ops.updateCalls)
+
+	record := storedRepository(t, "user/gone")
+	assert.Equal(t, db.StateSoftDeleted, record.State)
+	assert.Equal(t, deletedAt, record.DeletedAt)
+}
+
+func TestRun_RestoresReappearedRepository(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "back.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/back", SSHURL: "git@github.com:user/back.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{FullName: "user/back", Directory: "back.git", State: db.StateSoftDeleted, DeletedAt: time.Now()},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "back.git")}, ops.updateCalls)
+
+	record := storedRepository(t, "user/back")
+	assert.Equal(t, db.StateActive, record.State)
+	assert.True(t, record.DeletedAt.IsZero())
+}
+
+func TestKnownDirectory(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings settings
+		fullName string
+		expected string
+	}{
+		{name: "flat user repository", settings: settings{layout: config.LayoutFlat}, fullName: "user/repo", expected: "/backup/repo.git"},
+		{name: "flat organization repository", settings: settings{layout: config.LayoutFlat, organizations: []string{"acme"}}, fullName: "acme/repo", expected: "/backup/acme/repo.git"},
+		{name: "owner layout", settings: settings{layout: config.LayoutOwner}, fullName: "user/repo", expected: "/backup/user/repo.git"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, knownDirectory("/backup", tc.settings, tc.fullName))
+		})
+	}
+}
+
+func TestRun_MovesRenamedRepositoryInsteadOfCloning(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "old-name.git"), 0755)
+	os.WriteFile(filepath.Join(dir, "old-name.git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+
+	repos := []forge.Repository{
+		{ID: 7, FullName: "user/new-name", SSHURL: "git@github.com:user/new-name.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 7, FullName: "user/old-name", Directory: "old-name.git", State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	renamed := filepath.Join(dir, "new-name.git")
+	assert.NoDirExists(t, filepath.Join(dir, "old-name.git"))
+	assert.FileExists(t, filepath.Join(renamed, "HEAD"))
+	assert.Empty(t, ops.cloneCalls)
+	assert.Equal(t, []string{renamed}, ops.updateCalls)
+	assert.Equal(t, []cloneCall{{sshURL: "git@github.com:user/new-name.git", targetDirectory: renamed}}, ops.setRemoteURLCalls)
+
+	repositories, err := db.GetGitHubRepositories()
+	assert.NoError(t, err)
+	assert.Len(t, repositories, 1)
+	assert.Equal(t, "user/new-name", repositories[0].FullName)
+	assert.Equal(t, "new-name.git", repositories[0].Directory)
+	assert.Equal(t, db.StateActive, repositories[0].State)
+}
+
+func TestRun_MovesTransferredRepositoryToNewOwner(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "alice", "tools.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 7, FullName: "acme/tools", SSHURL: "git@github.com:acme/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 7, FullName: "alice/tools", Directory: filepath.Join("alice", "tools.git"), State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), dir, settings{layout: config.LayoutOwner})
+	assert.NoError(t, err)
+
+	assert.NoDirExists(t, filepath.Join(dir, "alice", "tools.git"))
+	assert.DirExists(t, filepath.Join(dir, "acme", "tools.git"))
+	assert.Empty(t, ops.cloneCalls)
+	assert.Len(t, ops.setRemoteURLCalls, 1)
+}
+
+func TestRun_RenameUpdatesRemoteWhenDirectoryIsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	mirror := filepath.Join(dir, "ids", "7.git")
+	os.MkdirAll(mirror, 0755)
+
+	repos := []forge.Repository{
+		{ID: 7, FullName: "user/new-name", SSHURL: "git@github.com:user/new-name.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 7, FullName: "user/old-name", Directory: filepath.Join("ids", "7.git"), State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), dir, settings{layout: "ids/{id}.git"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []cloneCall{{sshURL: "git@github.com:user/new-name.git", targetDirectory: mirror}}, ops.setRemoteURLCalls)
+	assert.Equal(t, []string{mirror}, ops.updateCalls)
+}
+
+func TestRun_RenameDoesNotOverwriteExistingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "old-name.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "new-name.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 7, FullName: "user/new-name", SSHURL: "git@github.com:user/new-name.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 7, FullName: "user/old-name", Directory: "old-name.git", State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	assert.DirExists(t, filepath.Join(dir, "old-name.git"))
+	assert.Empty(t, ops.setRemoteURLCalls)
+}
+
+func TestRun_RepositoryCreatedUnderRenamedName(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "project.git"), 0755)
+	os.WriteFile(filepath.Join(dir, "project.git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+
+	repos := []forge.Repository{
+		{ID: 2, FullName: "user/project", SSHURL: "git@github.com:user/project.git"},
+		{ID: 1, FullName: "user/project-old", SSHURL: "git@github.com:user/project-old.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/project", Directory: "project.git", State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	renamed := filepath.Join(dir, "project-old.git")
+	assert.FileExists(t, filepath.Join(renamed, "HEAD"))
+	assert.Equal(t, []string{renamed}, ops.updateCalls)
+	assert.Len(t, ops.cloneCalls, 1)
+	assert.Equal(t, filepath.Join(dir, "project.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, int64(1), storedRepository(t, "user/project-old").ID)
+	assert.Equal(t, int64(2), storedRepository(t, "user/project").ID)
+}
+
+func TestRun_SameNameWithoutStoredIDIsNotARename(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "repo1.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{FullName: "user/repo1", State: db.StateActive},
+	})
+
+	_, err := run(context.Background(), dir, testSettings())
+	assert.NoError(t, err)
+
+	assert.Empty(t, ops.setRemoteURLCalls)
+	record := storedRepository(t, "user/repo1")
+	assert.Equal(t, int64(1), record.ID)
+}
+
+func TestRun_SameIDOnAnotherSourceIsNotARename(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "tools.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	mockSources(t, map[string][]forge.Repository{
+		"gitlab": {{ID: 1, FullName: "platform/cli", SSHURL: "git@gitlab.com:platform/cli.git"}},
+	}, nil)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/tools", Directory: "tools.git", State: db.StateActive},
+	})
+
+	s := testSettings()
+	s.sources = []config.SourceConfig{{Name: "gitlab", Type: config.SourceTypeGitLab}}
+	_, err := run(context.Background(), dir, s)
+	assert.NoError(t, err)
+
+	assert.Empty(t, ops.setRemoteURLCalls)
+	assert.Equal(t, []string{filepath.Join(dir, "tools.git")}, ops.updateCalls)
+	assert.Equal(t, filepath.Join(dir, "gitlab", "platform", "cli.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, db.StateActive, storedRepository(t, "user/tools").State)
+	assert.Equal(t, "gitlab", storedRepository(t, "gitlab/platform/cli").Source)
+}
+
+func TestRun_SoftDeletesRepositoryMissingFromSource(t *testing.T) {
+	dir := t.TempDir()
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	seedInventory(t, []db.Repository{
+		{Source: "gitlab", ID: 7, FullName: "gitlab/platform/old", Directory: filepath.Join("gitlab", "old.git"), State: db.StateActive},
+	})
+
+	s := testSettings()
+	s.sources = []config.SourceConfig{{Name: "gitlab", Type: config.SourceTypeGitLab}}
+	result, err := plan(context.Background(), dir, s)
+	assert.NoError(t, err)
+	assert.Equal(t, []PlannedRepository{
+		{FullName: "gitlab/platform/old", Action: actionSoftDelete, Directory: filepath.Join(dir, "gitlab", "old.git"), Detail: "no longer listed on gitlab"},
+	}, result.Repositories)
+
+	_, err = run(context.Background(), dir, s)
+	assert.NoError(t, err)
+	assert.Equal(t, db.StateSoftDeleted, storedRepository(t, "gitlab/platform/old").State)
+}
+
+func TestInventory_UpToDate(t *testing.T) {
+	fetchedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pushedAt := time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC)
+	mockNow(t, fetchedAt.Add(time.Hour))
+
+	fetched := db.Repository{FullName: "user/repo1", State: db.StateActive, PushedAt: pushedAt, LastFetchedAt: fetchedAt}
+	refresh := settings{fullRefresh: 24 * time.Hour}
+
+	tests := []struct {
+		name     string
+		record   db.Repository
+		pushedAt time.Time
+		settings settings
+		expected bool
+	}{
+		{"nothing pushed", fetched, pushedAt, refresh, true},
+		{"pushed since fetch", fetched, pushedAt.Add(time.Minute), refresh, false},
+		{"forced", fetched, pushedAt, settings{fullRefresh: 24 * time.Hour, force: true}, false},
+		{"full refresh due", fetched, pushedAt, settings{fullRefresh: 30 * time.Minute}, false},
+		{"never fetched", db.Repository{FullName: "user/repo1", PushedAt: pushedAt}, pushedAt, refresh, false},
+		{"no pushed_at recorded", db.Repository{FullName: "user/repo1", LastFetchedAt: fetchedAt}, pushedAt, refresh, false},
+		{"no pushed_at listed", fetched, time.Time{}, refresh, false},
+		{"last sync failed", db.Repository{FullName: "user/repo1", PushedAt: pushedAt, LastFetchedAt: fetchedAt, FailureCount: 1}, pushedAt, refresh, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &inventory{repositories: []db.Repository{tt.record}}
+			inv.reindex()
+
+			repository := forge.Repository{ID: 1, FullName: "user/repo1", PushedAt: tt.pushedAt}
+			assert.Equal(t, tt.expected, inv.upToDate(repository, tt.settings))
+		})
+	}
+
+	inv := &inventory{}
+	inv.reindex()
+	assert.False(t, inv.upToDate(forge.Repository{FullName: "user/new", PushedAt: pushedAt}, refresh))
+}
+
+func TestRun_SkipsFetchWhenNothingWasPushed(t *testing.T) {
+	fetchedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pushedAt := time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC)
+	mockNow(t, fetchedAt.Add(time.Hour))
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "quiet.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "busy.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/quiet", SSHURL: "git@github.com:user/quiet.git", PushedAt: pushedAt},
+		{ID: 2, FullName: "user/busy", SSHURL: "git@github.com:user/busy.git", PushedAt: pushedAt.Add(time.Hour)},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/quiet", State: db.StateActive, PushedAt: pushedAt, LastFetchedAt: fetchedAt, Refs: map[string]string{"refs/heads/main": "aaaa"}},
+		{ID: 2, FullName: "user/busy", State: db.StateActive, PushedAt: pushedAt, LastFetchedAt: fetchedAt},
+	})
+
+	s := testSettings()
+	s.fullRefresh = 24 * time.Hour
+	result, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "busy.git")}, ops.updateCalls)
+	assert.Equal(t, OutcomeUnchanged, result.Repositories[0].Outcome)
+	assert.Equal(t, OutcomeUpdated, result.Repositories[1].Outcome)
+
+	quiet := storedRepository(t, "user/quiet")
+	assert.Equal(t, fetchedAt, quiet.LastFetchedAt)
+	assert.Equal(t, map[string]string{"refs/heads/main": "aaaa"}, quiet.Refs)
+	busy := storedRepository(t, "user/busy")
+	assert.Equal(t, fetchedAt.Add(time.Hour), busy.LastFetchedAt)
+	assert.Equal(t, pushedAt.Add(time.Hour), busy.PushedAt)
+}
+
+func TestRun_ForceFetchesUnchangedRepositories(t *testing.T) {
+	fetchedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pushedAt := time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC)
+	mockNow(t, fetchedAt.Add(time.Hour))
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "quiet.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/quiet", SSHURL: "git@github.com:user/quiet.git", PushedAt: pushedAt},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/quiet", State: db.StateActive, PushedAt: pushedAt, LastFetchedAt: fetchedAt},
+	})
+
+	s := testSettings()
+	s.fullRefresh = 24 * time.Hour
+	s.force = true
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.updateCalls, 1)
+}
+
+func TestRun_ClonesMissingMirrorEvenWhenNothingWasPushed(t *testing.T) {
+	fetchedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pushedAt := time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC)
+	mockNow(t, fetchedAt.Add(time.Hour))
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/quiet", SSHURL: "git@github.com:user/quiet.git", PushedAt: pushedAt},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/quiet", State: db.StateActive, PushedAt: pushedAt, LastFetchedAt: fetchedAt},
+	})
+
+	s := testSettings()
+	s.fullRefresh = 24 * time.Hour
+	_, err := run(context.Background(), t.TempDir(), s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, 1)
+}
@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+)
+
+// repositoryOptions are the settings that can be overridden per repository.
+// A zero fetchInterval or retention means there is no override. source is
+// set for repositories of other sources, whose token and username replace the
+// GitHub credentials even when empty.
+type repositoryOptions struct {
+	cloneProtocol string
+	cloneTimeout  time.Duration
+	updateTimeout time.Duration
+	fetchInterval time.Duration
+	fetchRefspecs []string
+	lfs           bool
+	retention     time.Duration
+	source        bool
+	token         string
+	username      string
+}
+
+// forRepository merges the global settings with every override matching
+// fullName. Overrides are ordered from least to most specific, so the most
+// specific value wins.
+func (s settings) forRepository(fullName string) repositoryOptions {
+	options := repositoryOptions{
+		cloneProtocol: s.cloneProtocol,
+		cloneTimeout:  s.cloneTimeout,
+		updateTimeout: s.updateTimeout,
+		lfs:           s.lfs,
+	}
+
+	for _, override := range s.overrides {
+		if !override.Matches(fullName) {
+			continue
+		}
+		if override.CloneProtocol != "" {
+			options.cloneProtocol = override.CloneProtocol
+		}
+		if override.CloneTimeout > 0 {
+			options.cloneTimeout = override.CloneTimeout
+		}
+		if override.UpdateTimeout > 0 {
+			options.updateTimeout = override.UpdateTimeout
+		}
+		if override.FetchInterval > 0 {
+			options.fetchInterval = override.FetchInterval
+		}
+		if override.FetchRefspecs != nil {
+			options.fetchRefspecs = override.FetchRefspecs
+		}
+		if override.LFS != nil {
+			options.lfs = *override.LFS
+		}
+		if override.Retention > 0 {
+			options.retention = override.Retention
+		}
+	}
+	return options
+}
+
+// auth is the authentication git gets for a repository. The credentials are
+// only handed to git for repositories cloned over HTTPS.
+func (o repositoryOptions) auth(auth gitAuth) gitAuth {
+	if o.source {
+		auth.token = o.token
+		auth.username = o.username
+	}
+	if o.cloneProtocol != config.CloneProtocolHTTPS {
+		auth.token = ""
+		auth.username = ""
+	}
+	return auth
+}
@@ -0,0 +1,141 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func overrideSettings(overrides ...config.RepositoryConfig) settings {
+	s := testSettings()
+	s.cloneProtocol = config.CloneProtocolSSH
+	s.cloneTimeout = time.Hour
+	s.updateTimeout = 30 * time.Minute
+	s.overrides = overrides
+	return s
+}
+
+func TestSettings_ForRepository(t *testing.T) {
+	enabled, disabled := true, false
+	s := overrideSettings(
+		config.RepositoryConfig{Pattern: "acme/*", CloneProtocol: config.CloneProtocolHTTPS, UpdateTimeout: time.Hour, LFS: &enabled},
+		config.RepositoryConfig{Pattern: "acme/monorepo", UpdateTimeout: 3 * time.Hour, FetchRefspecs: []string{"+refs/notes/*:refs/notes/*"}, LFS: &disabled},
+	)
+
+	assert.Equal(t, repositoryOptions{
+		cloneProtocol: config.CloneProtocolSSH,
+		cloneTimeout:  time.Hour,
+		updateTimeout: 30 * time.Minute,
+	}, s.forRepository("user/tools"))
+	assert.Equal(t, repositoryOptions{
+		cloneProtocol: config.CloneProtocolHTTPS,
+		cloneTimeout:  time.Hour,
+		updateTimeout: time.Hour,
+		lfs:           true,
+	}, s.forRepository("acme/tools"))
+	assert.Equal(t, repositoryOptions{
+		cloneProtocol: config.CloneProtocolHTTPS,
+		cloneTimeout:  time.Hour,
+		updateTimeout: 3 * time.Hour,
+		fetchRefspecs: []string{"+refs/notes/*:refs/notes/*"},
+	}, s.forRepository("Acme/Monorepo"))
+}
+
+func TestRepositoryOptions_Auth(t *testing.T) {
+	auth := gitAuth{token: "test-token", sshCommand: "ssh"}
+
+	assert.Equal(t, auth, repositoryOptions{cloneProtocol: config.CloneProtocolHTTPS}.auth(auth))
+	assert.Equal(t, gitAuth{sshCommand: "ssh"}, repositoryOptions{cloneProtocol: config.CloneProtocolSSH}.auth(auth))
+	assert.Equal(t, gitAuth{token: "glpat-token", sshCommand: "ssh"}, repositoryOptions{cloneProtocol: config.CloneProtocolHTTPS, source: true, token: "glpat-token"}.auth(auth))
+	assert.Equal(t, gitAuth{sshCommand: "ssh"}, repositoryOptions{cloneProtocol: config.CloneProtocolSSH, source: true, token: "glpat-token"}.auth(auth))
+	assert.Equal(t, gitAuth{username: "alice", token: "app-password", sshCommand: "ssh"}, repositoryOptions{cloneProtocol: config.CloneProtocolHTTPS, source: true, token: "app-password", username: "alice"}.auth(auth))
+	assert.Equal(t, gitAuth{sshCommand: "ssh"}, repositoryOptions{cloneProtocol: config.CloneProtocolSSH, source: true, token: "app-password", username: "alice"}.auth(auth))
+	assert.Equal(t, gitAuth{sshCommand: "ssh"}, repositoryOptions{cloneProtocol: config.CloneProtocolHTTPS, source: true}.auth(auth))
+}
+
+func TestRun_AppliesRepositoryOverrides(t *testing.T) {
+	dir := t.TempDir()
+	enabled := true
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git", CloneURL: "https://github.com/user/tools.git"},
+		{ID: 2, FullName: "acme/monorepo", SSHURL: "git@github.com:acme/monorepo.git", CloneURL: "https://github.com/acme/monorepo.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	s := overrideSettings(config.RepositoryConfig{
+		Pattern:       "acme/monorepo",
+		CloneProtocol: config.CloneProtocolHTTPS,
+		FetchRefspecs: []string{"+refs/notes/*:refs/notes/*"},
+		LFS:           &enabled,
+	})
+	s.auth = gitAuth{token: "test-token"}
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "git@github.com:user/tools.git", ops.cloneCalls[0].sshURL)
+	assert.Equal(t, gitAuth{}, ops.cloneCalls[0].auth)
+	assert.Equal(t, "https://github.com/acme/monorepo.git", ops.cloneCalls[1].sshURL)
+	assert.Equal(t, gitAuth{token: "test-token"}, ops.cloneCalls[1].auth)
+
+	monorepo := filepath.Join(dir, "monorepo.git")
+	assert.Equal(t, []fetchRefspecsCall{{monorepo, []string{"+refs/notes/*:refs/notes/*"}, gitAuth{token: "test-token"}}}, ops.fetchRefspecsCalls)
+	assert.Equal(t, []string{monorepo}, ops.lfsFetchCalls)
+}
+
+func TestRun_FailsRepositoryWhenLFSFetchFails(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git"}}
+
+	ops := newMockGitOps()
+	ops.lfsFetchErr = errors.New("git: 'lfs' is not a git command")
+	setupMocks(t, repos, nil, ops)
+
+	s := overrideSettings()
+	s.lfs = true
+	s.retry = testRetryPolicy(1)
+	result, err := run(context.Background(), dir, s)
+
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	assert.Equal(t, OutcomeFailed, result.Repositories[0].Outcome)
+	assert.ErrorContains(t, result.Repositories[0].Err, "'lfs' is not a git command")
+}
+
+func TestRun_FetchIntervalLimitsFetches(t *testing.T) {
+	current := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	mockNow(t, current)
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "vendored.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "tools.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/vendored", SSHURL: "git@github.com:user/vendored.git", PushedAt: current.Add(-time.Hour)},
+		{ID: 2, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git", PushedAt: current.Add(-time.Hour)},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	lastFetched := current.Add(-3 * 24 * time.Hour)
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/vendored", Directory: "vendored.git", State: db.StateActive, LastFetchedAt: lastFetched, PushedAt: lastFetched},
+		{ID: 2, FullName: "user/tools", Directory: "tools.git", State: db.StateActive, LastFetchedAt: lastFetched, PushedAt: lastFetched},
+	})
+
+	s := overrideSettings(config.RepositoryConfig{Pattern: "user/vendored", FetchInterval: 7 * 24 * time.Hour})
+	s.fullRefresh = 24 * time.Hour
+	result, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "tools.git")}, ops.updateCalls)
+	assert.Equal(t, OutcomeUnchanged, result.Repositories[0].Outcome)
+	assert.Equal(t, lastFetched, storedRepository(t, "user/vendored").LastFetchedAt)
+}
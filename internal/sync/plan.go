@@ -0,0 +1,188 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+)
+
+// Actions that only appear in plans; the others are shared with mirror.
+const (
+	actionRename       = "rename"
+	actionSoftDelete   = "soft-delete"
+	actionSkipFiltered = "skip-filtered"
+)
+
+// SyncPlan is what a sync would do, worked out without running git or
+// writing state.
+type SyncPlan struct {
+	Repositories []PlannedRepository `json:"repositories"`
+}
+
+// PlannedRepository is the action a sync would take for one repository.
+// Previous is the full name a renamed repository was recorded under.
+type PlannedRepository struct {
+	FullName  string `json:"full_name"`
+	Action    string `json:"action"`
+	Directory string `json:"directory"`
+	Previous  string `json:"previous,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// plan lists the repositories like run does and compares them with the
+// backup directory and the lockfile.
+func plan(ctx context.Context, dir string, settings settings) (*SyncPlan, error) {
+	targets, err := listTargets(ctx, dir, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, filtered := filterTargets(targets, settings.filter)
+
+	inv, err := readInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncPlan{Repositories: []PlannedRepository{}}
+	listed := make(map[string]bool, len(targets))
+	for _, skipped := range filtered {
+		if record := inv.find(skipped.target.source, skipped.target.repository); record != nil {
+			listed[record.FullName] = true
+		}
+	}
+	// A renamed repository moves its mirror away, so a new repository listed
+	// under its old name is cloned rather than updated.
+	vacated := make(map[string]string)
+	for _, target := range targets {
+		if record := inv.find(target.source, target.repository); record != nil && record.FullName != target.repository.FullName && record.Directory != "" {
+			vacated[filepath.Join(dir, record.Directory)] = target.repository.FullName
+		}
+	}
+	for _, target := range targets {
+		repository := target.repository
+		listed[repository.FullName] = true
+		planned := PlannedRepository{FullName: repository.FullName, Directory: target.directory}
+
+		record := inv.find(target.source, repository)
+		skip := ""
+		if record != nil {
+			listed[record.FullName] = true
+			skip = inv.skipFetch(target, settings)
+		}
+
+		switch {
+		case record != nil && record.FullName != repository.FullName:
+			planned.Action = actionRename
+			planned.Previous = record.FullName
+			planned.Detail = "renamed from " + record.FullName
+			if record.Directory != "" && filepath.Join(dir, record.Directory) != target.directory {
+				planned.Detail += ", moves " + filepath.Join(dir, record.Directory)
+			}
+		case record == nil && vacated[target.directory] != "":
+			planned.Action = actionClone
+			planned.Detail = "after " + vacated[target.directory] + " moves out"
+		case isDirectory(target.directory) && skip != "":
+			planned.Action = actionSkipUnchanged
+			planned.Detail = skip
+		case isDirectory(target.directory):
+			planned.Action = actionUpdate
+		default:
+			planned.Action = actionClone
+			if legacy := legacyDirectory(dir, repository); target.source == "" && legacy != target.directory && isDirectory(legacy) {
+				planned.Detail = "migrates " + legacy + " instead if its remote matches"
+			}
+		}
+
+		if record != nil && record.State == db.StateSoftDeleted {
+			planned.Detail = join(planned.Detail, "restores soft-deleted repository")
+		}
+		result.Repositories = append(result.Repositories, planned)
+	}
+
+	for _, skipped := range filtered {
+		result.Repositories = append(result.Repositories, PlannedRepository{
+			FullName:  skipped.target.repository.FullName,
+			Action:    actionSkipFiltered,
+			Directory: skipped.target.directory,
+			Detail:    skipReason(skipped.rule),
+		})
+	}
+
+	for _, record := range inv.repositories {
+		if listed[record.FullName] || record.State != db.StateActive {
+			continue
+		}
+
+		directory := knownDirectory(dir, settings, record.FullName)
+		if record.Directory != "" {
+			directory = filepath.Join(dir, record.Directory)
+		}
+		result.Repositories = append(result.Repositories, PlannedRepository{
+			FullName:  record.FullName,
+			Action:    actionSoftDelete,
+			Directory: directory,
+			Detail:    "no longer listed on " + forgeName(record.Source),
+		})
+	}
+
+	return result, nil
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func join(detail, addition string) string {
+	if detail == "" {
+		return addition
+	}
+	return detail + ", " + addition
+}
+
+// WriteSummary writes the plan as a table, one repository per row. Rows
+// without a detail are not padded with trailing spaces.
+func (p *SyncPlan) WriteSummary(w io.Writer) error {
+	var buffer bytes.Buffer
+	table := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ACTION\tREPOSITORY\tDIRECTORY\tDETAIL")
+	for _, repository := range p.Repositories {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", repository.Action, repository.FullName, repository.Directory, repository.Detail)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for line := range strings.Lines(buffer.String()) {
+		if _, err := io.WriteString(w, strings.TrimRight(line, " \n")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the plan as an indented JSON document.
+func (p *SyncPlan) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// Plan works out what Run would do with the same options, without running
+// git or writing the lockfile, reports or known hosts.
+func Plan(ctx context.Context, options Options) (*SyncPlan, error) {
+	if _, err := config.Get(); err != nil {
+		return nil, err
+	}
+	return plan(ctx, getBackupDirectory(), loadSettings(options))
+}
@@ -0,0 +1,182 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	at := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	mockNow(t, at)
+	dir := t.TempDir()
+	for _, name := range []string{"busy.git", "quiet.git", "old-name.git"} {
+		os.MkdirAll(filepath.Join(dir, name), 0755)
+	}
+
+	pushedAt := at.Add(-48 * time.Hour)
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/new", SSHURL: "git@github.com:user/new.git"},
+		{ID: 2, FullName: "user/busy", SSHURL: "git@github.com:user/busy.git", PushedAt: at.Add(-time.Hour)},
+		{ID: 3, FullName: "user/quiet", SSHURL: "git@github.com:user/quiet.git", PushedAt: pushedAt},
+		{ID: 4, FullName: "user/new-name", SSHURL: "git@github.com:user/new-name.git"},
+		{ID: 5, FullName: "user/back", SSHURL: "git@github.com:user/back.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 2, FullName: "user/busy", Directory: "busy.git", State: db.StateActive, PushedAt: pushedAt, LastFetchedAt: at.Add(-time.Hour)},
+		{ID: 3, FullName: "user/quiet", Directory: "quiet.git", State: db.StateActive, PushedAt: pushedAt, LastFetchedAt: at.Add(-time.Hour)},
+		{ID: 4, FullName: "user/old-name", Directory: "old-name.git", State: db.StateActive},
+		{ID: 5, FullName: "user/back", Directory: "back.git", State: db.StateSoftDeleted},
+		{ID: 6, FullName: "user/gone", Directory: "gone.git", State: db.StateActive},
+		{ID: 7, FullName: "user/long-gone", Directory: "long-gone.git", State: db.StateSoftDeleted},
+	})
+	lockfile, _ := os.ReadFile(db.LockfilePath())
+
+	s := testSettings()
+	s.fullRefresh = 24 * time.Hour
+	result, err := plan(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PlannedRepository{
+		{FullName: "user/new", Action: actionClone, Directory: filepath.Join(dir, "new.git")},
+		{FullName: "user/busy", Action: actionUpdate, Directory: filepath.Join(dir, "busy.git")},
+		{FullName: "user/quiet", Action: actionSkipUnchanged, Directory: filepath.Join(dir, "quiet.git"), Detail: "nothing pushed since 2026-02-27T02:00:00Z"},
+		{FullName: "user/new-name", Action: actionRename, Directory: filepath.Join(dir, "new-name.git"), Previous: "user/old-name", Detail: "renamed from user/old-name, moves " + filepath.Join(dir, "old-name.git")},
+		{FullName: "user/back", Action: actionClone, Directory: filepath.Join(dir, "back.git"), Detail: "restores soft-deleted repository"},
+		{FullName: "user/gone", Action: actionSoftDelete, Directory: filepath.Join(dir, "gone.git"), Detail: "no longer listed on GitHub"},
+	}, result.Repositories)
+
+	assert.Empty(t, ops.cloneCalls)
+	assert.Empty(t, ops.updateCalls)
+	assert.Empty(t, ops.setRemoteURLCalls)
+	assert.DirExists(t, filepath.Join(dir, "old-name.git"))
+	stored, _ := os.ReadFile(db.LockfilePath())
+	assert.Equal(t, lockfile, stored)
+}
+
+func TestPlan_RepositoryCreatedUnderRenamedName(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "project.git"), 0755)
+	repos := []forge.Repository{
+		{ID: 2, FullName: "user/project", SSHURL: "git@github.com:user/project.git"},
+		{ID: 1, FullName: "user/project-old", SSHURL: "git@github.com:user/project-old.git"},
+	}
+	setupMocks(t, repos, nil, newMockGitOps())
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/project", Directory: "project.git", State: db.StateActive},
+	})
+
+	result, err := plan(context.Background(), dir, testSettings())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PlannedRepository{
+		{FullName: "user/project", Action: actionClone, Directory: filepath.Join(dir, "project.git"), Detail: "after user/project-old moves out"},
+		{FullName: "user/project-old", Action: actionRename, Directory: filepath.Join(dir, "project-old.git"), Previous: "user/project", Detail: "renamed from user/project, moves " + filepath.Join(dir, "project.git")},
+	}, result.Repositories)
+}
+
+func TestPlan_WritesNothingWithoutLockfile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backup")
+	repos := []forge.Repository{{ID: 1, FullName: "user/new", SSHURL: "git@github.com:user/new.git"}}
+	setupMocks(t, repos, nil, newMockGitOps())
+
+	result, err := plan(context.Background(), dir, testSettings())
+
+	assert.NoError(t, err)
+	assert.Equal(t, actionClone, result.Repositories[0].Action)
+	assert.NoFileExists(t, db.LockfilePath())
+	assert.NoDirExists(t, dir)
+}
+
+func TestPlan_MentionsLegacyMirror(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "tools.git"), 0755)
+	repos := []forge.Repository{{ID: 1, FullName: "alice/tools", SSHURL: "git@github.com:alice/tools.git"}}
+	setupMocks(t, repos, nil, newMockGitOps())
+
+	s := testSettings()
+	s.layout = "owner"
+	result, err := plan(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, actionClone, result.Repositories[0].Action)
+	assert.Equal(t, "migrates "+filepath.Join(dir, "tools.git")+" instead if its remote matches", result.Repositories[0].Detail)
+}
+
+func TestSyncPlan_WriteSummary(t *testing.T) {
+	result := &SyncPlan{Repositories: []PlannedRepository{
+		{FullName: "user/new", Action: actionClone, Directory: "/backup/new.git"},
+		{FullName: "user/gone", Action: actionSoftDelete, Directory: "/backup/gone.git", Detail: "no longer listed on GitHub"},
+	}}
+
+	var output bytes.Buffer
+	err := result.WriteSummary(&output)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `ACTION       REPOSITORY  DIRECTORY         DETAIL
+clone        user/new    /backup/new.git
+soft-delete  user/gone   /backup/gone.git  no longer listed on GitHub
+`, output.String())
+}
+
+func TestSyncPlan_WriteJSON(t *testing.T) {
+	result := &SyncPlan{Repositories: []PlannedRepository{
+		{FullName: "user/new-name", Action: actionRename, Directory: "/backup/new-name.git", Previous: "user/old-name"},
+	}}
+
+	var output bytes.Buffer
+	err := result.WriteJSON(&output)
+
+	assert.NoError(t, err)
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Equal(t, map[string]any{"repositories": []any{map[string]any{
+		"full_name": "user/new-name",
+		"action":    "rename",
+		"directory": "/backup/new-name.git",
+		"previous":  "user/old-name",
+	}}}, decoded)
+}
+
+func TestPlan_InvalidConfigReturnsError(t *testing.T) {
+	useInvalidConfig(t)
+
+	result, err := Plan(context.Background(), Options{})
+
+	assert.ErrorContains(t, err, "[Config]")
+	assert.Nil(t, result)
+}
+
+func TestPlan_SkipsRepositoryWithinFetchInterval(t *testing.T) {
+	at := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	mockNow(t, at)
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "monorepo.git"), 0755)
+
+	repos := []forge.Repository{{ID: 1, FullName: "user/monorepo", SSHURL: "git@github.com:user/monorepo.git", PushedAt: at.Add(-time.Minute)}}
+	setupMocks(t, repos, nil, newMockGitOps())
+	seedInventory(t, []db.Repository{
+		{ID: 1, FullName: "user/monorepo", Directory: "monorepo.git", State: db.StateActive, PushedAt: at.Add(-time.Hour), LastFetchedAt: at.Add(-time.Hour)},
+	})
+
+	s := testSettings()
+	s.overrides = []config.RepositoryConfig{{Pattern: "user/monorepo", FetchInterval: 6 * time.Hour}}
+	result, err := plan(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PlannedRepository{
+		{FullName: "user/monorepo", Action: actionSkipUnchanged, Directory: filepath.Join(dir, "monorepo.git"), Detail: "fetched at 2026-03-01T01:00:00Z, within fetch_interval 6h0m0s"},
+	}, result.Repositories)
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+)
+
+// gitvaultNamespace holds refs created by GitVault itself. They do not exist
+// upstream, so they are left out of ref snapshots.
+const (
+	gitvaultNamespace  = "refs/gitvault/"
+	preservedNamespace = gitvaultNamespace + "preserved/"
+)
+
+// preservedRefName is where the old tip of ref is kept after the update at
+// timestamp rewound or deleted it.
+func preservedRefName(timestamp, ref string) string {
+	return preservedNamespace + timestamp + "/" + strings.TrimPrefix(ref, "refs/")
+}
+
+// syntheticRefs match refs the forge computes rather than anyone pushes.
+// GitHub recreates the test merge of every open pull request whenever its
+// base branch moves, so their history is not worth keeping.
+var syntheticRefs = []string{"refs/pull/*/merge"}
+
+func isSyntheticRef(ref string) bool {
+	return slices.ContainsFunc(syntheticRefs, func(pattern string) bool {
+		matched, _ := path.Match(pattern, ref)
+		return matched
+	})
+}
+
+// classifyRefUpdates lists how every ref moved between two snapshots of a
+// mirror, in ref order, leaving out synthetic refs. A ref whose old tip is
+// not an ancestor of its new one was force-pushed.
+func classifyRefUpdates(ctx context.Context, directory string, before, after map[string]string) ([]db.RefUpdate, error) {
+	refs := slices.Sorted(maps.Keys(after))
+	for ref := range before {
+		if _, exists := after[ref]; !exists {
+			refs = append(refs, ref)
+		}
+	}
+	slices.Sort(refs)
+
+	var updates []db.RefUpdate
+	for _, ref := range refs {
+		update := db.RefUpdate{Ref: ref, Old: before[ref], New: after[ref]}
+		switch {
+		case update.Old == update.New || isSyntheticRef(ref):
+			continue
+		case update.Old == "":
+			update.Kind = db.RefCreated
+		case update.New == "":
+			update.Kind = db.RefDeleted
+		default:
+			fastForward, err := isAncestorFn(ctx, directory, update.Old, update.New)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare %s %s..%s: %w", ref, update.Old, update.New, err)
+			}
+			update.Kind = db.RefForced
+			if fastForward {
+				update.Kind = db.RefFastForward
+			}
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+// preserveRewrittenRefs pins the old tip of every ref an update deleted or
+// rewound by a force push under refs/gitvault/preserved/, so it stays
+// reachable in the mirror.
+func preserveRewrittenRefs(ctx context.Context, logger *slog.Logger, directory string, updates []db.RefUpdate) ([]PreservedRef, error) {
+	timestamp := now().UTC().Format("20060102T150405Z")
+	var preserved []PreservedRef
+	for _, update := range updates {
+		if update.Kind != db.RefForced && update.Kind != db.RefDeleted {
+			continue
+		}
+
+		name := preservedRefName(timestamp, update.Ref)
+		if err := updateRefFn(ctx, directory, name, update.Old); err != nil {
+			return preserved, fmt.Errorf("failed to preserve %s at %s: %w", update.Ref, update.Old, err)
+		}
+
+		rescued := PreservedRef{Ref: update.Ref, Object: update.Old, PreservedAs: name, Deleted: update.Kind == db.RefDeleted}
+		logger.Warn("preserved rewritten ref", "ref", update.Ref, "object", update.Old, "preserved_as", name, "deleted", rescued.Deleted)
+		preserved = append(preserved, rescued)
+	}
+	return preserved, nil
+}
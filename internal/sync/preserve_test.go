@@ -0,0 +1,246 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+var preserveTime = time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+
+func TestPreservedRefName(t *testing.T) {
+	assert.Equal(t, "refs/gitvault/preserved/20260301T020000Z/heads/main", preservedRefName("20260301T020000Z", "refs/heads/main"))
+	assert.Equal(t, "refs/gitvault/preserved/20260301T020000Z/tags/v1", preservedRefName("20260301T020000Z", "refs/tags/v1"))
+}
+
+func TestClassifyRefUpdates(t *testing.T) {
+	ops := newMockGitOps()
+	ops.fastForwards["aaaa..bbbb"] = true
+	setupMocks(t, nil, nil, ops)
+
+	before := map[string]string{
+		"refs/heads/main":    "aaaa",
+		"refs/heads/rewound": "cccc",
+		"refs/heads/deleted": "dddd",
+		"refs/heads/same":    "eeee",
+		"refs/pull/1/head":   "2222",
+		"refs/pull/1/merge":  "3333",
+	}
+	after := map[string]string{
+		"refs/heads/main":    "bbbb",
+		"refs/heads/rewound": "ffff",
+		"refs/heads/same":    "eeee",
+		"refs/heads/new":     "1111",
+		"refs/pull/1/head":   "4444",
+		"refs/pull/1/merge":  "5555",
+	}
+
+	updates, err := classifyRefUpdates(context.Background(), "/backup/tools.git", before, after)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []db.RefUpdate{
+		{Ref: "refs/heads/deleted", Old: "dddd", Kind: db.RefDeleted},
+		{Ref: "refs/heads/main", Old: "aaaa", New: "bbbb", Kind: db.RefFastForward},
+		{Ref: "refs/heads/new", New: "1111", Kind: db.RefCreated},
+		{Ref: "refs/heads/rewound", Old: "cccc", New: "ffff", Kind: db.RefForced},
+		{Ref: "refs/pull/1/head", Old: "2222", New: "4444", Kind: db.RefForced},
+	}, updates)
+}
+
+func TestIsSyntheticRef(t *testing.T) {
+	assert.True(t, isSyntheticRef("refs/pull/42/merge"))
+	assert.False(t, isSyntheticRef("refs/pull/42/head"))
+	assert.False(t, isSyntheticRef("refs/heads/merge"))
+	assert.False(t, isSyntheticRef("refs/pull/42/merge/extra"))
+}
+
+func TestPreserveRewrittenRefs(t *testing.T) {
+	mockNow(t, preserveTime)
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+
+	updates := []db.RefUpdate{
+		{Ref: "refs/heads/deleted", Old: "dddd", Kind: db.RefDeleted},
+		{Ref: "refs/heads/main", Old: "aaaa", New: "bbbb", Kind: db.RefFastForward},
+		{Ref: "refs/heads/new", New: "1111", Kind: db.RefCreated},
+		{Ref: "refs/heads/rewound", Old: "cccc", New: "ffff", Kind: db.RefForced},
+	}
+
+	preserved, err := preserveRewrittenRefs(context.Background(), slog.Default(), "/backup/tools.git", updates)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PreservedRef{
+		{Ref: "refs/heads/deleted", Object: "dddd", PreservedAs: "refs/gitvault/preserved/20260301T020000Z/heads/deleted", Deleted: true},
+		{Ref: "refs/heads/rewound", Object: "cccc", PreservedAs: "refs/gitvault/preserved/20260301T020000Z/heads/rewound"},
+	}, preserved)
+	assert.Equal(t, []updateRefCall{
+		{"/backup/tools.git", "refs/gitvault/preserved/20260301T020000Z/heads/deleted", "dddd"},
+		{"/backup/tools.git", "refs/gitvault/preserved/20260301T020000Z/heads/rewound", "cccc"},
+	}, ops.updateRefCalls)
+}
+
+func TestPreserveRewrittenRefs_FailsWhenRefCannotBeCreated(t *testing.T) {
+	ops := newMockGitOps()
+	ops.updateRefErr = errors.New("exit status 128")
+	setupMocks(t, nil, nil, ops)
+
+	_, err := preserveRewrittenRefs(context.Background(), slog.Default(), "/backup/tools.git",
+		[]db.RefUpdate{{Ref: "refs/heads/main", Old: "aaaa", Kind: db.RefDeleted}})
+
+	assert.EqualError(t, err, "failed to preserve refs/heads/main at aaaa: exit status 128")
+}
+
+func TestRun_ReportsPreservedRefs(t *testing.T) {
+	mockNow(t, preserveTime)
+	dir := t.TempDir()
+	directory := filepath.Join(dir, "tools.git")
+	os.MkdirAll(directory, 0755)
+
+	repos := []forge.Repository{{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git"}}
+
+	ops := newMockGitOps()
+	ops.refs[directory] = map[string]string{"refs/heads/main": "aaaa"}
+	ops.refsAfterUpdate[directory] = map[string]string{"refs/heads/main": "bbbb"}
+	setupMocks(t, repos, nil, ops)
+
+	result, err := run(context.Background(), dir, testSettings())
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeUpdated, result.Repositories[0].Outcome)
+	assert.Equal(t, []PreservedRef{
+		{Ref: "refs/heads/main", Object: "aaaa", PreservedAs: "refs/gitvault/preserved/20260301T020000Z/heads/main"},
+	}, result.Repositories[0].PreservedRefs)
+}
+
+func TestRun_FailsRepositoryWhenRefsCannotBePreserved(t *testing.T) {
+	dir := t.TempDir()
+	directory := filepath.Join(dir, "tools.git")
+	os.MkdirAll(directory, 0755)
+
+	repos := []forge.Repository{{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git"}}
+
+	ops := newMockGitOps()
+	ops.refs[directory] = map[string]string{"refs/heads/main": "aaaa"}
+	ops.refsAfterUpdate[directory] = map[string]string{}
+	ops.updateRefErr = errors.New("exit status 128")
+	setupMocks(t, repos, nil, ops)
+
+	result, err := run(context.Background(), dir, testSettings())
+
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	assert.Equal(t, OutcomeFailed, result.Repositories[0].Outcome)
+	assert.ErrorContains(t, result.Repositories[0].Err, "failed to preserve refs/heads/main at aaaa")
+}
+
+func runGit(t *testing.T, directory string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = directory
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output.String())
+	}
+	return strings.TrimSpace(output.String())
+}
+
+func TestMirror_PreservesForcePushedRefWithGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	mockNow(t, preserveTime)
+
+	upstream := t.TempDir()
+	runGit(t, upstream, "init", "--quiet", "--initial-branch=main")
+	runGit(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "first")
+	runGit(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "second")
+	runGit(t, upstream, "branch", "feature")
+	rewritten := runGit(t, upstream, "rev-parse", "main")
+
+	target := mirrorTarget{
+		repository: forge.Repository{ID: 1, FullName: "user/tools"},
+		directory:  filepath.Join(t.TempDir(), "tools.git"),
+		url:        upstream,
+	}
+	assert.NoError(t, gitCloneMirror(context.Background(), upstream, target.directory, gitAuth{}, io.Discard))
+
+	runGit(t, upstream, "checkout", "--quiet", "feature")
+	runGit(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "fast-forward")
+	runGit(t, upstream, "checkout", "--quiet", "main")
+	runGit(t, upstream, "reset", "--quiet", "--hard", "HEAD~1")
+	runGit(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "rewritten")
+
+	result := mirror(context.Background(), target, testSettings())
+
+	assert.NoError(t, result.err)
+	preservedAs := "refs/gitvault/preserved/20260301T020000Z/heads/main"
+	assert.Equal(t, []PreservedRef{{Ref: "refs/heads/main", Object: rewritten, PreservedAs: preservedAs}}, result.preserved)
+	assert.Equal(t, rewritten, runGit(t, target.directory, "rev-parse", preservedAs))
+	assert.Equal(t, runGit(t, upstream, "rev-parse", "main"), result.refs["refs/heads/main"])
+	assert.NotContains(t, result.refs, preservedAs)
+
+	kinds := make(map[string]string)
+	for _, update := range result.updates {
+		kinds[update.Ref] = update.Kind
+	}
+	assert.Equal(t, map[string]string{"refs/heads/main": db.RefForced, "refs/heads/feature": db.RefFastForward}, kinds)
+}
+
+func TestMirror_PrunesDeletedRefsButKeepsPreservedRefsWithGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	mockNow(t, preserveTime)
+
+	upstream := t.TempDir()
+	runGit(t, upstream, "init", "--quiet", "--initial-branch=main")
+	runGit(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "first")
+	runGit(t, upstream, "branch", "feature")
+	deleted := runGit(t, upstream, "rev-parse", "feature")
+
+	target := mirrorTarget{
+		repository: forge.Repository{ID: 1, FullName: "user/tools"},
+		directory:  filepath.Join(t.TempDir(), "tools.git"),
+		url:        upstream,
+	}
+	assert.NoError(t, gitCloneMirror(context.Background(), upstream, target.directory, gitAuth{}, io.Discard))
+	runGit(t, target.directory, "config", "fetch.prune", "true")
+
+	runGit(t, upstream, "branch", "--quiet", "-D", "feature")
+	result := mirror(context.Background(), target, testSettings())
+
+	assert.NoError(t, result.err)
+	preservedAs := "refs/gitvault/preserved/20260301T020000Z/heads/feature"
+	assert.Equal(t, []PreservedRef{{Ref: "refs/heads/feature", Object: deleted, PreservedAs: preservedAs, Deleted: true}}, result.preserved)
+	assert.NotContains(t, result.refs, "refs/heads/feature")
+
+	runGit(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "second")
+	result = mirror(context.Background(), target, testSettings())
+
+	assert.NoError(t, result.err)
+	assert.Equal(t, deleted, runGit(t, target.directory, "rev-parse", preservedAs))
+}
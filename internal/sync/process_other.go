@@ -0,0 +1,9 @@
+//go:build !unix
+
+package sync
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as it is: without process groups only git is
+// killed, and WaitDelay bounds how long its children can hold its output.
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package sync
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitCloneMirror_TimeoutStopsHungSSH(t *testing.T) {
+	// Skips git's probe of the ssh variant, so the hung command is the one
+	// that inherits git's output.
+	t.Setenv("GIT_SSH_VARIANT", "ssh")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	auth := gitAuth{sshCommand: "sh -c 'sleep 30' --"}
+
+	var output bytes.Buffer
+	start := time.Now()
+	err := gitCloneMirror(ctx, "ssh://git@example.com/user/repo1.git", filepath.Join(t.TempDir(), "repo1.git"), auth, &output)
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), gitWaitDelay, "the hung ssh child should be killed with git")
+}
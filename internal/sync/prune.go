@@ -0,0 +1,64 @@
+package sync
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+)
+
+// prune permanently removes the mirrors of repositories that have been
+// soft-deleted for longer than gracePeriod, or than the retention configured
+// for the repository.
+func prune(dir string, gracePeriod time.Duration, settings settings) error {
+	inv, err := loadInventory()
+	if err != nil {
+		return err
+	}
+
+	var expired []db.Repository
+	for _, record := range inv.repositories {
+		retention := gracePeriod
+		if options := settings.forRepository(record.FullName); options.retention > 0 {
+			retention = options.retention
+		}
+		if record.State == db.StateSoftDeleted && now().Sub(record.DeletedAt) >= retention {
+			expired = append(expired, record)
+		}
+	}
+
+	pruned := 0
+	for _, record := range expired {
+		if !filepath.IsLocal(record.Directory) {
+			slog.Error("refusing to prune mirror outside the backup directory", "repository", record.FullName, "dir", record.Directory)
+			continue
+		}
+
+		directory := filepath.Join(dir, record.Directory)
+		if err := os.RemoveAll(directory); err != nil {
+			slog.Error("failed to prune mirror", "repository", record.FullName, "dir", directory, "error", err)
+			continue
+		}
+
+		slog.Info("pruned soft-deleted mirror", "repository", record.FullName, "dir", directory, "deleted_at", record.DeletedAt)
+		inv.remove(record.FullName)
+		pruned++
+	}
+
+	if err := inv.save(); err != nil {
+		return err
+	}
+
+	slog.Info("prune completed successfully", "pruned", pruned)
+	return nil
+}
+
+func Prune(gracePeriod time.Duration) error {
+	if _, err := config.Get(); err != nil {
+		return err
+	}
+	return prune(getBackupDirectory(), gracePeriod, loadSettings(Options{}))
+}
@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPrune(t *testing.T, repositories []db.Repository) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(t.TempDir(), "gitvault.lock.json"))
+	seedInventory(t, repositories)
+
+	for _, repository := range repositories {
+		os.MkdirAll(filepath.Join(dir, repository.Directory), 0755)
+	}
+	return dir
+}
+
+func TestPrune_RemovesExpiredMirrors(t *testing.T) {
+	current := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	mockNow(t, current)
+
+	dir := setupPrune(t, []db.Repository{
+		{FullName: "user/active", Directory: "active.git", State: db.StateActive},
+		{FullName: "user/old", Directory: "old.git", State: db.StateSoftDeleted, DeletedAt: current.Add(-31 * 24 * time.Hour)},
+		{FullName: "user/recent", Directory: "recent.git", State: db.StateSoftDeleted, DeletedAt: current.Add(-24 * time.Hour)},
+	})
+
+	err := prune(dir, 30*24*time.Hour, testSettings())
+	assert.NoError(t, err)
+
+	assert.DirExists(t, filepath.Join(dir, "active.git"))
+	assert.NoDirExists(t, filepath.Join(dir, "old.git"))
+	assert.DirExists(t, filepath.Join(dir, "recent.git"))
+
+	repositories, err := db.GetGitHubRepositories()
+	assert.NoError(t, err)
+	assert.Len(t, repositories, 2)
+	assert.Equal(t, "user/active", repositories[0].FullName)
+	assert.Equal(t, "user/recent", repositories[1].FullName)
+}
+
+func TestPrune_ZeroGracePeriodRemovesEverySoftDeleted(t *testing.T) {
+	dir := setupPrune(t, []db.Repository{
+		{FullName: "user/a", Directory: "a.git", State: db.StateSoftDeleted, DeletedAt: time.Now()},
+		{FullName: "acme/b", Directory: "acme/b.git", State: db.StateSoftDeleted, DeletedAt: time.Now()},
+	})
+
+	err := prune(dir, 0, testSettings())
+	assert.NoError(t, err)
+
+	assert.NoDirExists(t, filepath.Join(dir, "a.git"))
+	assert.NoDirExists(t, filepath.Join(dir, "acme", "b.git"))
+
+	repositories, err := db.GetGitHubRepositories()
+	assert.NoError(t, err)
+	assert.Empty(t, repositories)
+}
+
+func TestPrune_RefusesDirectoryOutsideBackup(t *testing.T) {
+	dir := setupPrune(t, nil)
+	seedInventory(t, []db.Repository{
+		{FullName: "user/escape", Directory: "../escape.git", State: db.StateSoftDeleted, DeletedAt: time.Now().Add(-time.Hour)},
+	})
+
+	err := prune(dir, 0, testSettings())
+	assert.NoError(t, err)
+
+	repositories, err := db.GetGitHubRepositories()
+	assert.NoError(t, err)
+	assert.Len(t, repositories, 1)
+}
+
+func TestPrune_RepositoryRetentionOverridesGracePeriod(t *testing.T) {
+	current := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	mockNow(t, current)
+
+	dir := setupPrune(t, []db.Repository{
+		{FullName: "acme/archive", Directory: "archive.git", State: db.StateSoftDeleted, DeletedAt: current.Add(-31 * 24 * time.Hour)},
+		{FullName: "user/scratch", Directory: "scratch.git", State: db.StateSoftDeleted, DeletedAt: current.Add(-2 * time.Hour)},
+	})
+
+	s := testSettings()
+	s.overrides = []config.RepositoryConfig{
+		{Pattern: "acme/*", Retention: 365 * 24 * time.Hour},
+		{Pattern: "user/scratch", Retention: time.Hour},
+	}
+	err := prune(dir, 30*24*time.Hour, s)
+	assert.NoError(t, err)
+
+	assert.DirExists(t, filepath.Join(dir, "archive.git"))
+	assert.NoDirExists(t, filepath.Join(dir, "scratch.git"))
+}
@@ -0,0 +1,166 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+)
+
+const (
+	reportsDirectory = "reports"
+	reportPrefix     = "report-"
+	reportSuffix     = ".json"
+)
+
+// Report is the machine-readable record of a sync run.
+type Report struct {
+	StartedAt    time.Time          `json:"started_at"`
+	FinishedAt   time.Time          `json:"finished_at"`
+	Version      string             `json:"version"`
+	Error        string             `json:"error,omitempty"`
+	Summary      map[Outcome]int    `json:"summary"`
+	Repositories []RepositoryReport `json:"repositories"`
+}
+
+// RepositoryReport is what a sync run did with a single repository.
+type RepositoryReport struct {
+	FullName         string         `json:"full_name"`
+	Directory        string         `json:"directory"`
+	Action           Outcome        `json:"action"`
+	Reason           string         `json:"reason,omitempty"`
+	DurationSeconds  float64        `json:"duration_seconds"`
+	BytesTransferred int64          `json:"bytes_transferred"`
+	RefChanges       []RefChange    `json:"ref_changes,omitempty"`
+	PreservedRefs    []PreservedRef `json:"preserved_refs,omitempty"`
+	Error            string         `json:"error,omitempty"`
+}
+
+func newReport(startedAt, finishedAt time.Time, version string, result *SyncResult, err error) Report {
+	report := Report{
+		StartedAt:    startedAt.UTC(),
+		FinishedAt:   finishedAt.UTC(),
+		Version:      version,
+		Summary:      make(map[Outcome]int, len(outcomes)),
+		Repositories: []RepositoryReport{},
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	if result == nil {
+		return report
+	}
+
+	for _, outcome := range outcomes {
+		report.Summary[outcome] = result.Count(outcome)
+	}
+	for _, repository := range result.Repositories {
+		entry := RepositoryReport{
+			FullName:         repository.FullName,
+			Directory:        repository.Directory,
+			Action:           repository.Outcome,
+			Reason:           repository.Reason,
+			DurationSeconds:  repository.Duration.Seconds(),
+			BytesTransferred: repository.BytesTransferred,
+			RefChanges:       repository.RefChanges,
+			PreservedRefs:    repository.PreservedRefs,
+		}
+		if repository.Err != nil {
+			entry.Error = repository.Err.Error()
+		}
+		report.Repositories = append(report.Repositories, entry)
+	}
+	return report
+}
+
+// getReportsDirectory keeps reports next to the lockfile, which is the
+// directory GitVault already persists its state in.
+func getReportsDirectory() string {
+	return filepath.Join(filepath.Dir(db.LockfilePath()), reportsDirectory)
+}
+
+// saveReport writes the report into the reports directory, removes all but
+// the newest retention reports, and copies it to path if one is configured.
+func saveReport(report Report, path string, retention int) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	dir := getReportsDirectory()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create reports directory %s: %w", dir, err)
+	}
+
+	name := reportPrefix + report.StartedAt.Format("20060102T150405.000Z") + reportSuffix
+	if err := writeFileAtomically(filepath.Join(dir, name), data); err != nil {
+		return err
+	}
+
+	if err := pruneReports(dir, retention); err != nil {
+		return err
+	}
+
+	if path != "" {
+		if err := writeFileAtomically(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomically replaces path in one step, so a monitoring agent never
+// reads a half-written report.
+func writeFileAtomically(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".gitvault-report-*")
+	if err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	return nil
+}
+
+// pruneReports removes the oldest reports beyond retention. Report names sort
+// by the time their run started.
+func pruneReports(dir string, retention int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list reports in %s: %w", dir, err)
+	}
+
+	var reports []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, reportPrefix) && strings.HasSuffix(name, reportSuffix) {
+			reports = append(reports, name)
+		}
+	}
+	slices.Sort(reports)
+
+	for len(reports) > retention {
+		if err := os.Remove(filepath.Join(dir, reports[0])); err != nil {
+			return fmt.Errorf("failed to remove old report %s: %w", reports[0], err)
+		}
+		reports = reports[1:]
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func readReport(t *testing.T, path string) Report {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(data, &report))
+	return report
+}
+
+func storedReports(t *testing.T) []string {
+	t.Helper()
+
+	reports, err := filepath.Glob(filepath.Join(getReportsDirectory(), reportPrefix+"*"+reportSuffix))
+	assert.NoError(t, err)
+	return reports
+}
+
+func TestNewReport(t *testing.T) {
+	startedAt := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(90 * time.Second)
+	result := &SyncResult{Repositories: []RepositoryResult{
+		{
+			FullName:         "user/busy",
+			Directory:        "/backup/busy.git",
+			Outcome:          OutcomeUpdated,
+			Duration:         1500 * time.Millisecond,
+			BytesTransferred: 2048,
+			RefChanges:       []RefChange{{Ref: "refs/heads/main", Old: "aaaa", New: "bbbb"}},
+		},
+		{FullName: "acme/broken", Directory: "/backup/acme/broken.git", Outcome: OutcomeFailed, Err: errors.New("exit status 128")},
+	}}
+
+	report := newReport(startedAt, finishedAt, "v0.0.1", result, fmt.Errorf("%w: 1 of 2", ErrRepositoriesFailed))
+
+	assert.Equal(t, Report{
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Version:    "v0.0.1",
+		Error:      "repositories failed to sync: 1 of 2",
+		Summary: map[Outcome]int{
+			OutcomeCloned:    0,
+			OutcomeUpdated:   1,
+			OutcomeUnchanged: 0,
+			OutcomeFailed:    1,
+			OutcomeSkipped:   0,
+			OutcomeFiltered:  0,
+		},
+		Repositories: []RepositoryReport{
+			{
+				FullName:         "user/busy",
+				Directory:        "/backup/busy.git",
+				Action:           OutcomeUpdated,
+				DurationSeconds:  1.5,
+				BytesTransferred: 2048,
+				RefChanges:       []RefChange{{Ref: "refs/heads/main", Old: "aaaa", New: "bbbb"}},
+			},
+			{FullName: "acme/broken", Directory: "/backup/acme/broken.git", Action: OutcomeFailed, Error: "exit status 128"},
+		},
+	}, report)
+}
+
+func TestSaveReport_KeepsNewestReports(t *testing.T) {
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(t.TempDir(), "gitvault.lock.json"))
+	startedAt := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	for day := range 5 {
+		report := Report{StartedAt: startedAt.AddDate(0, 0, day), Version: "v0.0.1"}
+		assert.NoError(t, saveReport(report, "", 3))
+	}
+
+	reports := storedReports(t)
+	assert.Len(t, reports, 3)
+	assert.Equal(t, "report-20260303T020000.000Z.json", filepath.Base(reports[0]))
+	assert.Equal(t, "report-20260305T020000.000Z.json", filepath.Base(reports[2]))
+	assert.Equal(t, startedAt.AddDate(0, 0, 4), readReport(t, reports[2]).StartedAt)
+}
+
+func TestSaveReport_WritesConfiguredPath(t *testing.T) {
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(t.TempDir(), "gitvault.lock.json"))
+	path := filepath.Join(t.TempDir(), "latest.json")
+	report := Report{StartedAt: time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC), Version: "v0.0.1"}
+
+	assert.NoError(t, saveReport(report, path, 3))
+	assert.NoError(t, saveReport(report, path, 3))
+
+	assert.Equal(t, report.StartedAt, readReport(t, path).StartedAt)
+	assert.Len(t, storedReports(t), 1)
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, entries, 1)
+}
+
+func TestRunAndReport_WritesReport(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	s := testSettings()
+	s.version = "v0.0.1"
+	s.reportRetention = 3
+	_, err := runAndReport(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	reports := storedReports(t)
+	assert.Len(t, reports, 1)
+	report := readReport(t, reports[0])
+	assert.Equal(t, "v0.0.1", report.Version)
+	assert.Empty(t, report.Error)
+	assert.Equal(t, 1, report.Summary[OutcomeCloned])
+	assert.Equal(t, "user/repo1", report.Repositories[0].FullName)
+	assert.Equal(t, OutcomeCloned, report.Repositories[0].Action)
+}
+
+func TestRunAndReport_ReportsRunThatFailedBeforeMirroring(t *testing.T) {
+	ops := newMockGitOps()
+	setupMocks(t, nil, errors.New("API error"), ops)
+
+	s := testSettings()
+	s.reportRetention = 3
+	_, err := runAndReport(context.Background(), t.TempDir(), s)
+
+	assert.Error(t, err)
+	reports := storedReports(t)
+	assert.Len(t, reports, 1)
+	report := readReport(t, reports[0])
+	assert.Equal(t, "failed to fetch repositories from GitHub: API error", report.Error)
+	assert.Empty(t, report.Repositories)
+}
@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrRepositoriesFailed is returned by Run when at least one repository could
+// not be cloned or updated.
+var ErrRepositoriesFailed = errors.New("repositories failed to sync")
+
+// Outcome is what a sync run did with a single repository.
+type Outcome string
+
+const (
+	OutcomeCloned    Outcome = "cloned"
+	OutcomeUpdated   Outcome = "updated"
+	OutcomeUnchanged Outcome = "unchanged"
+	OutcomeFailed    Outcome = "failed"
+	OutcomeSkipped   Outcome = "skipped"
+	OutcomeFiltered  Outcome = "filtered"
+)
+
+var outcomes = []Outcome{OutcomeCloned, OutcomeUpdated, OutcomeUnchanged, OutcomeFailed, OutcomeSkipped, OutcomeFiltered}
+
+// RepositoryResult is the outcome of a single repository in a sync run.
+// Reason tells which filter rule skipped a filtered repository.
+type RepositoryResult struct {
+	FullName         string
+	Directory        string
+	Outcome          Outcome
+	Reason           string
+	Err              error
+	Duration         time.Duration
+	BytesTransferred int64
+	RefChanges       []RefChange
+	PreservedRefs    []PreservedRef
+}
+
+// PreservedRef is a ref tip that an update rewound or deleted and that was
+// kept under PreservedAs so its history stays in the mirror.
+type PreservedRef struct {
+	Ref         string `json:"ref"`
+	Object      string `json:"object"`
+	PreservedAs string `json:"preserved_as"`
+	Deleted     bool   `json:"deleted,omitempty"`
+}
+
+// RefChange is a ref that was created, moved or deleted by a sync. Old is
+// empty for created refs and New is empty for deleted ones.
+type RefChange struct {
+	Ref string `json:"ref"`
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// refChanges lists the differences between two sets of ref tips, sorted by
+// ref name.
+func refChanges(previous, current map[string]string) []RefChange {
+	var changes []RefChange
+	for ref, object := range current {
+		if previous[ref] != object {
+			changes = append(changes, RefChange{Ref: ref, Old: previous[ref], New: object})
+		}
+	}
+	for ref, object := range previous {
+		if _, ok := current[ref]; !ok {
+			changes = append(changes, RefChange{Ref: ref, Old: object})
+		}
+	}
+	slices.SortFunc(changes, func(a, b RefChange) int {
+		return strings.Compare(a.Ref, b.Ref)
+	})
+	return changes
+}
+
+// SyncResult collects the outcome of every repository in a sync run.
+type SyncResult struct {
+	Repositories []RepositoryResult
+}
+
+func (r *SyncResult) add(result RepositoryResult) {
+	r.Repositories = append(r.Repositories, result)
+}
+
+// Count returns how many repositories ended with the given outcome.
+func (r *SyncResult) Count(outcome Outcome) int {
+	count := 0
+	for _, repository := range r.Repositories {
+		if repository.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed returns the repositories that could not be cloned or updated.
+func (r *SyncResult) Failed() []RepositoryResult {
+	var failed []RepositoryResult
+	for _, repository := range r.Repositories {
+		if repository.Outcome == OutcomeFailed {
+			failed = append(failed, repository)
+		}
+	}
+	return failed
+}
+
+// WriteSummary prints the number of repositories per outcome followed by the
+// error of every failed repository.
+func (r *SyncResult) WriteSummary(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "OUTCOME\tREPOSITORIES")
+	for _, outcome := range outcomes {
+		fmt.Fprintf(table, "%s\t%d\n", outcome, r.Count(outcome))
+	}
+	fmt.Fprintf(table, "total\t%d\n", len(r.Repositories))
+
+	var preserved bool
+	for _, repository := range r.Repositories {
+		for _, ref := range repository.PreservedRefs {
+			if !preserved {
+				fmt.Fprintln(table)
+				fmt.Fprintln(table, "PRESERVED\tREF\tAS")
+				preserved = true
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\n", repository.FullName, ref.Ref, ref.PreservedAs)
+		}
+	}
+
+	if failed := r.Failed(); len(failed) > 0 {
+		fmt.Fprintln(table)
+		fmt.Fprintln(table, "FAILED\tERROR")
+		for _, repository := range failed {
+			fmt.Fprintf(table, "%s\t%v\n", repository.FullName, repository.Err)
+		}
+	}
+
+	return table.Flush()
+}
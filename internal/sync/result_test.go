@@ -0,0 +1,175 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncResult_WriteSummary(t *testing.T) {
+	result := &SyncResult{Repositories: []RepositoryResult{
+		{FullName: "user/new", Outcome: OutcomeCloned},
+		{FullName: "user/busy", Outcome: OutcomeUpdated},
+		{FullName: "user/quiet", Outcome: OutcomeUnchanged},
+		{FullName: "user/other", Outcome: OutcomeUnchanged},
+		{FullName: "acme/broken", Outcome: OutcomeFailed, Err: errors.New("exit status 128: ERROR: Repository not found.")},
+	}}
+
+	var output bytes.Buffer
+	err := result.WriteSummary(&output)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `OUTCOME    REPOSITORIES
+cloned     1
+updated    1
+unchanged  2
+failed     1
+skipped    0
+filtered   0
+total      5
+
+FAILED       ERROR
+acme/broken  exit status 128: ERROR: Repository not found.
+`, output.String())
+}
+
+func TestSyncResult_WriteSummaryWithoutFailures(t *testing.T) {
+	result := &SyncResult{Repositories: []RepositoryResult{{FullName: "user/new", Outcome: OutcomeCloned}}}
+
+	var output bytes.Buffer
+	err := result.WriteSummary(&output)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, output.String(), "FAILED")
+}
+
+func TestSyncResult_WriteSummaryListsPreservedRefs(t *testing.T) {
+	result := &SyncResult{Repositories: []RepositoryResult{
+		{FullName: "user/tools", Outcome: OutcomeUpdated, PreservedRefs: []PreservedRef{
+			{Ref: "refs/heads/main", Object: "aaaa", PreservedAs: "refs/gitvault/preserved/20260301T020000Z/heads/main"},
+		}},
+	}}
+
+	var output bytes.Buffer
+	err := result.WriteSummary(&output)
+
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), `PRESERVED   REF              AS
+user/tools  refs/heads/main  refs/gitvault/preserved/20260301T020000Z/heads/main
+`)
+}
+
+func TestRun_ReportsOutcomePerRepository(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "busy.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "quiet.git"), 0755)
+
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/new", SSHURL: "git@github.com:user/new.git"},
+		{ID: 2, FullName: "user/busy", SSHURL: "git@github.com:user/busy.git"},
+		{ID: 3, FullName: "user/quiet", SSHURL: "git@github.com:user/quiet.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.refs[filepath.Join(dir, "busy.git")] = map[string]string{"refs/heads/main": "bbbb"}
+	ops.refs[filepath.Join(dir, "quiet.git")] = map[string]string{"refs/heads/main": "cccc"}
+	setupMocks(t, repos, nil, ops)
+	seedInventory(t, []db.Repository{
+		{ID: 2, FullName: "user/busy", State: db.StateActive, Refs: map[string]string{"refs/heads/main": "aaaa"}},
+		{ID: 3, FullName: "user/quiet", State: db.StateActive, Refs: map[string]string{"refs/heads/main": "cccc"}},
+	})
+
+	result, err := run(context.Background(), dir, testSettings())
+
+	assert.NoError(t, err)
+	for index := range result.Repositories {
+		assert.Positive(t, result.Repositories[index].Duration)
+		result.Repositories[index].Duration = 0
+	}
+	assert.Equal(t, []RepositoryResult{
+		{FullName: "user/new", Directory: filepath.Join(dir, "new.git"), Outcome: OutcomeCloned},
+		{
+			FullName:   "user/busy",
+			Directory:  filepath.Join(dir, "busy.git"),
+			Outcome:    OutcomeUpdated,
+			RefChanges: []RefChange{{Ref: "refs/heads/main", Old: "aaaa", New: "bbbb"}},
+		},
+		{FullName: "user/quiet", Directory: filepath.Join(dir, "quiet.git"), Outcome: OutcomeUnchanged},
+	}, result.Repositories)
+}
+
+func TestRefChanges(t *testing.T) {
+	previous := map[string]string{
+		"refs/heads/main":    "aaaa",
+		"refs/heads/old":     "bbbb",
+		"refs/tags/v1":       "cccc",
+		"refs/heads/feature": "dddd",
+	}
+	current := map[string]string{
+		"refs/heads/main":    "eeee",
+		"refs/tags/v1":       "cccc",
+		"refs/heads/feature": "dddd",
+		"refs/heads/new":     "ffff",
+	}
+
+	assert.Equal(t, []RefChange{
+		{Ref: "refs/heads/main", Old: "aaaa", New: "eeee"},
+		{Ref: "refs/heads/new", New: "ffff"},
+		{Ref: "refs/heads/old", Old: "bbbb"},
+	}, refChanges(previous, current))
+	assert.Empty(t, refChanges(current, current))
+}
+
+func TestMirror_MeasuresBytesTransferred(t *testing.T) {
+	dir := t.TempDir()
+	target := mirrorTarget{
+		repository: forge.Repository{ID: 1, FullName: "user/repo1"},
+		directory:  filepath.Join(dir, "repo1.git"),
+	}
+	os.MkdirAll(filepath.Join(target.directory, "objects", "pack"), 0755)
+	os.WriteFile(filepath.Join(target.directory, "objects", "pack", "old.pack"), make([]byte, 100), 0644)
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	remoteUpdateFn = func(ctx context.Context, repository string, auth gitAuth, output io.Writer) error {
+		return os.WriteFile(filepath.Join(repository, "objects", "pack", "new.pack"), make([]byte, 250), 0644)
+	}
+
+	results := mirrorAll(context.Background(), []mirrorTarget{target}, workerSettings(1, time.Second))
+
+	assert.NoError(t, results[0].err)
+	assert.Equal(t, int64(250), results[0].bytes)
+}
+
+func TestRun_FailedRepositoriesAndShutdownAreBothReported(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	cloneMirrorFn = func(gitCtx context.Context, sshURL, targetDirectory string, auth gitAuth, output io.Writer) error {
+		cancel()
+		return errors.New("clone failed")
+	}
+
+	result, err := run(ctx, dir, workerSettings(1, time.Second))
+
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, result.Count(OutcomeSkipped))
+}
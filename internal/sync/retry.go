@@ -0,0 +1,229 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// retryPolicy bounds how often and how quickly a failed git operation is
+// attempted again.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// backoff returns the delay before the attempt following the given failed
+// one: exponential in attempt, capped at maxBackoff, and jittered to between
+// half and all of that so concurrent workers do not retry in lockstep.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.initialBackoff
+	for range attempt - 1 {
+		if delay >= p.maxBackoff/2 {
+			delay = p.maxBackoff
+			break
+		}
+		delay *= 2
+	}
+	delay = min(delay, p.maxBackoff)
+
+	if half := int64(delay / 2); half > 0 {
+		return time.Duration(half + rand.Int64N(half+1))
+	}
+	return delay
+}
+
+// gitError is a failed git operation together with the line of its output
+// that explains the failure.
+type gitError struct {
+	err       error
+	message   string
+	retryable bool
+}
+
+func (e *gitError) Error() string {
+	if e.message == "" {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%v: %s", e.err, e.message)
+}
+
+func (e *gitError) Unwrap() error {
+	return e.err
+}
+
+var (
+	permanentGitFailures = []string{
+		"repository not found",
+		"does not appear to be a git repository",
+		"permission denied",
+		"authentication failed",
+		"invalid username or password",
+		"could not read username",
+		"host key verification failed",
+		"access denied",
+	}
+	retryableGitFailures = []string{
+		"could not resolve host",
+		"connection timed out",
+		"operation timed out",
+		"connection reset",
+		"connection refused",
+		"connection closed",
+		"network is unreachable",
+		"temporary failure",
+		"the remote end hung up unexpectedly",
+		"early eof",
+		"rpc failed",
+		"broken pipe",
+		"internal server error",
+		"bad gateway",
+		"service unavailable",
+		"gateway timeout",
+	}
+	httpStatusPattern = regexp.MustCompile(`returned error: (\d{3})`)
+)
+
+// classifyGitError decides from the output of a failed git process whether
+// trying again can help. Failures that are not recognised are not retried.
+func classifyGitError(err error, output string) *gitError {
+	failure := &gitError{err: err, message: failureLine(output)}
+	lower := strings.ToLower(output)
+
+	if match := httpStatusPattern.FindStringSubmatch(lower); match != nil {
+		failure.retryable = match[1][0] == '5' || match[1] == "429"
+		return failure
+	}
+	for _, pattern := range permanentGitFailures {
+		if strings.Contains(lower, pattern) {
+			return failure
+		}
+	}
+	for _, pattern := range retryableGitFailures {
+		if strings.Contains(lower, pattern) {
+			failure.retryable = true
+			return failure
+		}
+	}
+	return failure
+}
+
+// failureLine picks the most telling line of git output: the first error
+// reported by git or the remote, or the last line if there is none.
+func failureLine(output string) string {
+	var last string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lower := strings.ToLower(line)
+		if strings.HasPrefix(lower, "fatal:") || strings.HasPrefix(lower, "error:") {
+			return line
+		}
+		last = line
+	}
+	return last
+}
+
+// outputTail passes git output through and keeps the last part of it for
+// classifying a failure.
+type outputTail struct {
+	output io.Writer
+	mu     sync.Mutex
+	tail   []byte
+}
+
+const outputTailSize = 4096
+
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	t.tail = append(t.tail, p...)
+	if len(t.tail) > outputTailSize {
+		t.tail = t.tail[len(t.tail)-outputTailSize:]
+	}
+	t.mu.Unlock()
+
+	return t.output.Write(p)
+}
+
+func (t *outputTail) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tail = t.tail[:0]
+}
+
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.tail)
+}
+
+// withRetry runs a git operation under a per-attempt timeout and retries it
+// according to policy while it fails with a retryable error. A timed out
+// attempt counts as retryable. No attempt is started once ctx is done.
+func withRetry(ctx context.Context, logger *slog.Logger, operation string, timeout time.Duration, policy retryPolicy, output io.Writer, fn func(context.Context, io.Writer) error) error {
+	tail := &outputTail{output: output}
+	attempts := max(policy.maxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		tail.reset()
+		err := runWithTimeout(ctx, timeout, func(attemptCtx context.Context) error {
+			return fn(attemptCtx, tail)
+		})
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		failure := classifyGitError(err, tail.String())
+		if errors.Is(err, errOperationTimedOut) {
+			failure = &gitError{err: fmt.Errorf("%s timed out after %s", operation, timeout), retryable: true}
+		}
+
+		if !failure.retryable || attempt >= attempts {
+			if attempt > 1 {
+				return fmt.Errorf("%s failed after %d attempts: %w", operation, attempt, failure)
+			}
+			return failure
+		}
+
+		delay := policy.backoff(attempt)
+		logger.Warn(operation+" failed, retrying", "attempt", attempt, "max_attempts", attempts, "backoff", delay, "error", failure)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return failure
+		}
+	}
+}
+
+var errOperationTimedOut = errors.New("operation timed out")
+
+// runWithTimeout runs fn under a context that expires after timeout, or never
+// if timeout is zero. A process killed by the deadline reports its exit
+// status rather than the deadline, so that case is recognised here.
+func runWithTimeout(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeoutCause(ctx, timeout, errOperationTimedOut)
+	defer cancel()
+
+	err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(attemptCtx), errOperationTimedOut) {
+		return fmt.Errorf("%w: %w", errOperationTimedOut, err)
+	}
+	return err
+}
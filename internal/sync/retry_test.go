@@ -0,0 +1,172 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryPolicy(maxAttempts int) retryPolicy {
+	return retryPolicy{maxAttempts: maxAttempts, initialBackoff: time.Millisecond, maxBackoff: 4 * time.Millisecond}
+}
+
+func TestClassifyGitError(t *testing.T) {
+	exit := errors.New("exit status 128")
+	tests := []struct {
+		name      string
+		output    string
+		retryable bool
+		message   string
+	}{
+		{"ssh repository not found", "ERROR: Repository not found.\nfatal: Could not read from remote repository.\n", false, "ERROR: Repository not found."},
+		{"ssh auth denied", "git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n", false, "fatal: Could not read from remote repository."},
+		{"https auth failed", "remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/acme/tools.git/'\n", false, "fatal: Authentication failed for 'https://github.com/acme/tools.git/'"},
+		{"http 404", "fatal: unable to access 'https://github.com/acme/tools.git/': The requested URL returned error: 404\n", false, "fatal: unable to access 'https://github.com/acme/tools.git/': The requested URL returned error: 404"},
+		{"http 502", "fatal: unable to access 'https://github.com/acme/tools.git/': The requested URL returned error: 502\n", true, "fatal: unable to access 'https://github.com/acme/tools.git/': The requested URL returned error: 502"},
+		{"dns", "ssh: Could not resolve hostname github.com: Temporary failure in name resolution\nfatal: Could not read from remote repository.\n", true, "fatal: Could not read from remote repository."},
+		{"hung up", "Connection to github.com closed by remote host.\nfatal: the remote end hung up unexpectedly\n", true, "fatal: the remote end hung up unexpectedly"},
+		{"unknown", "something unexpected\n", false, "something unexpected"},
+		{"no output", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := classifyGitError(exit, tt.output)
+
+			assert.Equal(t, tt.retryable, failure.retryable)
+			assert.Equal(t, tt.message, failure.message)
+			assert.ErrorIs(t, failure, exit)
+		})
+	}
+}
+
+func TestGitError_Error(t *testing.T) {
+	exit := errors.New("exit status 128")
+
+	assert.EqualError(t, &gitError{err: exit}, "exit status 128")
+	assert.EqualError(t, &gitError{err: exit, message: "fatal: repository not found"}, "exit status 128: fatal: repository not found")
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 10, initialBackoff: time.Second, maxBackoff: 10 * time.Second}
+
+	for range 50 {
+		first := policy.backoff(1)
+		assert.GreaterOrEqual(t, first, 500*time.Millisecond)
+		assert.LessOrEqual(t, first, time.Second)
+
+		third := policy.backoff(3)
+		assert.GreaterOrEqual(t, third, 2*time.Second)
+		assert.LessOrEqual(t, third, 4*time.Second)
+
+		capped := policy.backoff(8)
+		assert.GreaterOrEqual(t, capped, 5*time.Second)
+		assert.LessOrEqual(t, capped, 10*time.Second)
+	}
+}
+
+func TestWithRetry_RetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), slog.Default(), actionUpdate, 0, testRetryPolicy(3), io.Discard, func(ctx context.Context, output io.Writer) error {
+		attempts++
+		if attempts < 3 {
+			fmt.Fprintln(output, "fatal: the remote end hung up unexpectedly")
+			return errors.New("exit status 128")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), slog.Default(), actionUpdate, 0, testRetryPolicy(2), io.Discard, func(ctx context.Context, output io.Writer) error {
+		attempts++
+		fmt.Fprintln(output, "fatal: unable to access 'https://github.com/acme/tools.git/': The requested URL returned error: 503")
+		return errors.New("exit status 128")
+	})
+
+	assert.EqualError(t, err, "update failed after 2 attempts: exit status 128: fatal: unable to access 'https://github.com/acme/tools.git/': The requested URL returned error: 503")
+	assert.Equal(t, 2, attempts)
+}
+
+func TestWithRetry_DoesNotRetryPermanentFailures(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), slog.Default(), actionClone, 0, testRetryPolicy(3), io.Discard, func(ctx context.Context, output io.Writer) error {
+		attempts++
+		fmt.Fprintln(output, "ERROR: Repository not found.")
+		fmt.Fprintln(output, "fatal: Could not read from remote repository.")
+		return errors.New("exit status 128")
+	})
+
+	assert.EqualError(t, err, "exit status 128: ERROR: Repository not found.")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestWithRetry_RetriesTimedOutAttempts(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), slog.Default(), actionUpdate, 10*time.Millisecond, testRetryPolicy(2), io.Discard, func(ctx context.Context, output io.Writer) error {
+		attempts++
+		<-ctx.Done()
+		return errors.New("signal: killed")
+	})
+
+	assert.EqualError(t, err, "update failed after 2 attempts: update timed out after 10ms")
+	assert.Equal(t, 2, attempts)
+}
+
+func TestWithRetry_StopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempts := 0
+	policy := retryPolicy{maxAttempts: 5, initialBackoff: time.Hour, maxBackoff: time.Hour}
+	err := withRetry(ctx, slog.Default(), actionUpdate, 0, policy, io.Discard, func(ctx context.Context, output io.Writer) error {
+		attempts++
+		fmt.Fprintln(output, "fatal: the remote end hung up unexpectedly")
+		time.AfterFunc(5*time.Millisecond, cancel)
+		return errors.New("exit status 128")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestMirror_RetriesTransientUpdateFailure(t *testing.T) {
+	dir := t.TempDir()
+	target := mirrorTarget{
+		repository: forge.Repository{ID: 1, FullName: "user/repo1"},
+		directory:  filepath.Join(dir, "repo1.git"),
+	}
+	os.MkdirAll(target.directory, 0755)
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	attempts := 0
+	remoteUpdateFn = func(ctx context.Context, repository string, auth gitAuth, output io.Writer) error {
+		attempts++
+		if attempts == 1 {
+			fmt.Fprintln(output, "ssh: connect to host github.com port 22: Connection timed out")
+			return errors.New("exit status 128")
+		}
+		return nil
+	}
+
+	s := workerSettings(1, time.Second)
+	s.retry = testRetryPolicy(3)
+	results := mirrorAll(context.Background(), []mirrorTarget{target}, s)
+
+	assert.NoError(t, results[0].err)
+	assert.Equal(t, 2, attempts)
+}
@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+)
+
+const knownHostsFile = "gitvault.known_hosts"
+
+// sshSettings is the SSH identity and the host keys pinned per host.
+type sshSettings struct {
+	privateKeyPath string
+	knownHosts     map[string][]string
+}
+
+func (s sshSettings) configured() bool {
+	return s.privateKeyPath != "" || len(s.knownHosts) > 0
+}
+
+// getKnownHostsPath keeps the managed known_hosts file next to the lockfile.
+func getKnownHostsPath() string {
+	return filepath.Join(filepath.Dir(db.LockfilePath()), knownHostsFile)
+}
+
+// prepareSSH writes the pinned host keys to the managed known_hosts file and
+// returns the GIT_SSH_COMMAND git runs with. Without SSH settings git keeps
+// using the ssh configuration of the host and no command is returned.
+func prepareSSH(ssh sshSettings) (string, error) {
+	if !ssh.configured() {
+		return "", nil
+	}
+
+	options := []string{"ssh", "-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes"}
+
+	// Updates run ssh from the mirror directory, so every path it is given
+	// must be absolute.
+	if ssh.privateKeyPath != "" {
+		path, err := filepath.Abs(ssh.privateKeyPath)
+		if err != nil {
+			return "", fmt.Errorf("ssh private key: %w", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("ssh private key: %w", err)
+		}
+		options = append(options, "-i", shellQuote(path), "-o", "IdentitiesOnly=yes")
+	}
+
+	if len(ssh.knownHosts) > 0 {
+		path, err := filepath.Abs(getKnownHostsPath())
+		if err != nil {
+			return "", fmt.Errorf("known_hosts file: %w", err)
+		}
+		if err := writeKnownHosts(path, ssh.knownHosts); err != nil {
+			return "", err
+		}
+		options = append(options, "-o", "UserKnownHostsFile="+shellQuote(path), "-o", "GlobalKnownHostsFile=/dev/null")
+	}
+
+	return strings.Join(options, " "), nil
+}
+
+// writeKnownHosts replaces the managed known_hosts file with exactly the
+// configured keys, so a key removed from the configuration stops being
+// trusted on the next run.
+func writeKnownHosts(path string, knownHosts map[string][]string) error {
+	hosts := make([]string, 0, len(knownHosts))
+	for host := range knownHosts {
+		hosts = append(hosts, host)
+	}
+	slices.Sort(hosts)
+
+	var content strings.Builder
+	for _, host := range hosts {
+		for _, key := range knownHosts[host] {
+			fmt.Fprintf(&content, "%s %s\n", host, key)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write known_hosts file %s: %w", path, err)
+	}
+	return nil
+}
+
+// shellQuote quotes a path for GIT_SSH_COMMAND, which git runs through the
+// shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,155 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareSSH_WithoutSettings(t *testing.T) {
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(t.TempDir(), "gitvault.lock.json"))
+
+	command, err := prepareSSH(sshSettings{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, command)
+	assert.NoFileExists(t, getKnownHostsPath())
+}
+
+func TestPrepareSSH_PinsHostKeysAndIdentity(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state dir")
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(state, "gitvault.lock.json"))
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	os.WriteFile(key, []byte("private"), 0600)
+
+	command, err := prepareSSH(sshSettings{
+		privateKeyPath: key,
+		knownHosts: map[string][]string{
+			"gitlab.com":             {"ssh-ed25519 BBBB"},
+			"github.com":             {"ssh-ed25519 AAAA", "ecdsa-sha2-nistp256 CCCC"},
+			"[git.example.com]:2222": {"ssh-rsa DDDD"},
+		},
+	})
+
+	assert.NoError(t, err)
+	knownHosts := filepath.Join(state, "gitvault.known_hosts")
+	assert.Equal(t, "ssh -o BatchMode=yes -o StrictHostKeyChecking=yes -i '"+key+"' -o IdentitiesOnly=yes -o UserKnownHostsFile='"+knownHosts+"' -o GlobalKnownHostsFile=/dev/null", command)
+
+	content, err := os.ReadFile(knownHosts)
+	assert.NoError(t, err)
+	assert.Equal(t, "[git.example.com]:2222 ssh-rsa DDDD\ngithub.com ssh-ed25519 AAAA\ngithub.com ecdsa-sha2-nistp256 CCCC\ngitlab.com ssh-ed25519 BBBB\n", string(content))
+}
+
+func TestPrepareSSH_ReplacesManagedKnownHosts(t *testing.T) {
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(t.TempDir(), "gitvault.lock.json"))
+	os.WriteFile(getKnownHostsPath(), []byte("github.com ssh-rsa OLD\n"), 0644)
+
+	_, err := prepareSSH(sshSettings{knownHosts: map[string][]string{"github.com": {"ssh-ed25519 NEW"}}})
+
+	assert.NoError(t, err)
+	content, _ := os.ReadFile(getKnownHostsPath())
+	assert.Equal(t, "github.com ssh-ed25519 NEW\n", string(content))
+}
+
+func TestPrepareSSH_MissingPrivateKey(t *testing.T) {
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(t.TempDir(), "gitvault.lock.json"))
+
+	_, err := prepareSSH(sshSettings{privateKeyPath: "/nonexistent/id_ed25519"})
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorContains(t, err, "ssh private key")
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'/secrets/id_ed25519'`, shellQuote("/secrets/id_ed25519"))
+	assert.Equal(t, `'/secrets/it'\''s key'`, shellQuote("/secrets/it's key"))
+}
+
+func TestRun_ClonesWithManagedSSHCommand(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	s := testSettings()
+	s.ssh = sshSettings{knownHosts: map[string][]string{"github.com": {"ssh-ed25519 AAAA"}}}
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Contains(t, ops.cloneCalls[0].auth.sshCommand, "StrictHostKeyChecking=yes")
+	assert.Contains(t, ops.cloneCalls[0].auth.sshCommand, "UserKnownHostsFile="+shellQuote(getKnownHostsPath()))
+}
+
+func TestRun_FailsEarlyWithoutPrivateKey(t *testing.T) {
+	ops := newMockGitOps()
+	setupMocks(t, []forge.Repository{{ID: 1, FullName: "user/repo1"}}, nil, ops)
+
+	s := testSettings()
+	s.ssh = sshSettings{privateKeyPath: "/nonexistent/id_ed25519"}
+	_, err := run(context.Background(), t.TempDir(), s)
+
+	assert.ErrorContains(t, err, "failed to prepare ssh: ssh private key")
+	assert.Empty(t, ops.cloneCalls)
+}
+
+// fakeSSH puts an ssh on PATH that fails unless the identity and known_hosts
+// files it is given exist from its working directory, and otherwise serves
+// upstream.
+func fakeSSH(t *testing.T, upstream string) {
+	t.Helper()
+
+	bin := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	-i) test -f "$2" || { echo "missing identity $2" >&2; exit 1; } ;;
+	UserKnownHostsFile=*) test -f "${1#UserKnownHostsFile=}" || { echo "missing known_hosts ${1#UserKnownHostsFile=}" >&2; exit 1; } ;;
+	esac
+	shift
+done
+exec git upload-pack "$UPSTREAM"
+`
+	os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("UPSTREAM", upstream)
+}
+
+func TestRun_UpdatesWithRelativeLockfilePath(t *testing.T) {
+	dir := t.TempDir()
+	upstream := filepath.Join(t.TempDir(), "upstream.git")
+	runGit(t, t.TempDir(), "init", "--bare", upstream)
+	worktree := t.TempDir()
+	runGit(t, worktree, "init")
+	runGit(t, worktree, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "first")
+	runGit(t, worktree, "push", upstream, "HEAD:refs/heads/main")
+	mirror := filepath.Join(dir, "repo1.git")
+	runGit(t, dir, "init", "--bare", mirror)
+	runGit(t, mirror, "remote", "add", "origin", "git@github.com:user/repo1.git")
+
+	repos := []forge.Repository{{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"}}
+	ops := newMockGitOps()
+	ops.remoteURLs[mirror] = "git@github.com:user/repo1.git"
+	setupMocks(t, repos, nil, ops)
+	remoteUpdateFn = gitRemoteUpdate
+	fakeSSH(t, upstream)
+
+	t.Chdir(t.TempDir())
+	t.Setenv("GITVAULT_LOCKFILE_PATH", "gitvault.lock.json")
+	seedInventory(t, []db.Repository{{ID: 1, FullName: "user/repo1"}})
+	os.WriteFile("id_ed25519", []byte("private"), 0600)
+
+	s := testSettings()
+	s.ssh = sshSettings{privateKeyPath: "id_ed25519", knownHosts: map[string][]string{"github.com": {"ssh-ed25519 AAAA"}}}
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, runGit(t, mirror, "rev-parse", "refs/heads/main"))
+}
@@ -0,0 +1,87 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const stagingPrefix = ".gitvault-staging-"
+
+// stagingDirectory is the sibling a mirror is cloned into before it is
+// renamed into place. Its name is never produced by a layout, so a leftover
+// staging directory cannot be mistaken for a mirror.
+func stagingDirectory(directory string) string {
+	return filepath.Join(filepath.Dir(directory), stagingPrefix+filepath.Base(directory))
+}
+
+// cloneAtomically clones into the staging directory and only moves the result
+// to directory once the clone completed and passed verification. Whatever
+// happens, directory either does not exist or holds a complete mirror.
+func cloneAtomically(ctx context.Context, url, directory string, auth gitAuth, output io.Writer) error {
+	staging := stagingDirectory(directory)
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("failed to remove stale staging directory %s: %w", staging, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(directory), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(directory), err)
+	}
+
+	if err := cloneMirrorFn(ctx, url, staging, auth, output); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	if err := verifyMirrorFn(ctx, staging); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("cloned mirror failed verification: %w", err)
+	}
+
+	if err := os.Rename(staging, directory); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to move mirror into place: %w", err)
+	}
+
+	return nil
+}
+
+// removeStaleStagingDirectories deletes staging directories left behind by a
+// run that was killed before it could clean up after itself. Only the
+// directories the targets' mirrors are kept in are searched, and one that
+// cannot be read is skipped.
+func removeStaleStagingDirectories(targets []mirrorTarget) {
+	searched := make(map[string]bool)
+	for _, target := range targets {
+		parent := filepath.Dir(target.directory)
+		if searched[parent] {
+			continue
+		}
+		searched[parent] = true
+
+		entries, err := os.ReadDir(parent)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				slog.Warn("cannot look for stale staging directories", "dir", parent, "error", err)
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.HasPrefix(entry.Name(), stagingPrefix) {
+				continue
+			}
+			path := filepath.Join(parent, entry.Name())
+			slog.Info("removing stale staging directory", "dir", path)
+			if err := os.RemoveAll(path); err != nil {
+				slog.Warn("failed to remove stale staging directory", "dir", path, "error", err)
+			}
+		}
+	}
+}
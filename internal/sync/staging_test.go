@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStagingDirectory(t *testing.T) {
+	assert.Equal(t, "/backup/acme/.gitvault-staging-tools.git", stagingDirectory("/backup/acme/tools.git"))
+}
+
+func TestCloneAtomically_MovesVerifiedCloneIntoPlace(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "acme", "tools.git")
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+
+	err := cloneAtomically(context.Background(), "git@github.com:acme/tools.git", target, gitAuth{}, io.Discard)
+
+	assert.NoError(t, err)
+	assert.DirExists(t, target)
+	assert.NoDirExists(t, stagingDirectory(target))
+	assert.Equal(t, stagingDirectory(target), ops.cloneCalls[0].stagingDirectory)
+}
+
+func TestCloneAtomically_FailedCloneLeavesNothingBehind(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "tools.git")
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	cloneMirrorFn = func(ctx context.Context, sshURL, staging string, auth gitAuth, output io.Writer) error {
+		os.MkdirAll(filepath.Join(staging, "objects"), 0755)
+		return errors.New("exit status 128")
+	}
+
+	err := cloneAtomically(context.Background(), "git@github.com:user/tools.git", target, gitAuth{}, io.Discard)
+
+	assert.EqualError(t, err, "exit status 128")
+	assert.NoDirExists(t, target)
+	assert.NoDirExists(t, stagingDirectory(target))
+}
+
+func TestCloneAtomically_RejectsCloneThatFailsVerification(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "tools.git")
+
+	ops := newMockGitOps()
+	ops.verifyErr = errors.New("not a bare repository")
+	setupMocks(t, nil, nil, ops)
+
+	err := cloneAtomically(context.Background(), "git@github.com:user/tools.git", target, gitAuth{}, io.Discard)
+
+	assert.EqualError(t, err, "cloned mirror failed verification: not a bare repository")
+	assert.NoDirExists(t, target)
+	assert.NoDirExists(t, stagingDirectory(target))
+}
+
+func TestCloneAtomically_ReplacesLeftoverStagingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "tools.git")
+	os.MkdirAll(filepath.Join(stagingDirectory(target), "stale"), 0755)
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+
+	err := cloneAtomically(context.Background(), "git@github.com:user/tools.git", target, gitAuth{}, io.Discard)
+
+	assert.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(target, "stale"))
+}
+
+func TestRemoveStaleStagingDirectories(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".gitvault-staging-repo1.git", "objects"), 0755)
+	os.MkdirAll(filepath.Join(dir, "acme", ".gitvault-staging-tools.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "repo2.git", "objects"), 0755)
+	os.MkdirAll(filepath.Join(dir, "repo2.git", ".gitvault-staging-inside"), 0755)
+	targets := []mirrorTarget{
+		{directory: filepath.Join(dir, "repo1.git")},
+		{directory: filepath.Join(dir, "repo2.git")},
+		{directory: filepath.Join(dir, "acme", "tools.git")},
+		{directory: filepath.Join(dir, "missing", "repo3.git")},
+	}
+
+	removeStaleStagingDirectories(targets)
+
+	assert.NoDirExists(t, filepath.Join(dir, ".gitvault-staging-repo1.git"))
+	assert.NoDirExists(t, filepath.Join(dir, "acme", ".gitvault-staging-tools.git"))
+	assert.DirExists(t, filepath.Join(dir, "repo2.git", "objects"))
+	assert.DirExists(t, filepath.Join(dir, "repo2.git", ".gitvault-staging-inside"))
+}
+
+func TestRemoveStaleStagingDirectories_SkipsUnreadableDirectories(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "lost+found"), nil, 0644)
+	os.MkdirAll(filepath.Join(dir, "acme", ".gitvault-staging-repo2.git"), 0755)
+
+	removeStaleStagingDirectories([]mirrorTarget{
+		{directory: filepath.Join(dir, "lost+found", "repo1.git")},
+		{directory: filepath.Join(dir, "acme", "repo2.git")},
+	})
+
+	assert.NoDirExists(t, filepath.Join(dir, "acme", ".gitvault-staging-repo2.git"))
+}
+
+func TestRun_RemovesStaleStagingDirectories(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".gitvault-staging-gone.git"), 0755)
+	repos := []forge.Repository{{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"}}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	_, err := run(context.Background(), dir, testSettings())
+
+	assert.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(dir, ".gitvault-staging-gone.git"))
+	assert.DirExists(t, filepath.Join(dir, "repo1.git"))
+}
@@ -1,24 +1,124 @@
 package sync
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/konkasidiaris/gitvault/internal/bitbucket"
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/db"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/konkasidiaris/gitvault/internal/gitea"
 	"github.com/konkasidiaris/gitvault/internal/github"
+	"github.com/konkasidiaris/gitvault/internal/gitlab"
+	"github.com/konkasidiaris/gitvault/internal/static"
 )
 
 const defaultBackupDirectory = "/backup"
 
 var (
-	fetchGithubRepositories = getGithubRepositories
-	cloneMirrorFn           = gitCloneMirror
-	remoteUpdateFn          = gitRemoteUpdate
+	fetchGithubRepositories       = getGithubRepositories
+	fetchOrganizationRepositories = getOrganizationRepositories
+	fetchSourceRepositories       = getSourceRepositories
+	cloneMirrorFn                 = gitCloneMirror
+	remoteUpdateFn                = gitRemoteUpdate
+	listRefsFn                    = gitListRefs
+	verifyMirrorFn                = gitVerifyMirror
+	isAncestorFn                  = gitIsAncestor
+	updateRefFn                   = gitUpdateRef
+	fetchRefspecsFn               = gitFetchRefspecs
+	lfsFetchFn                    = gitLFSFetch
 )
 
+// Options are the command line overrides of a sync run. Zero values fall
+// back to the configuration file.
+type Options struct {
+	MaxParallel int
+	// Force fetches every repository, even those whose pushed_at has not
+	// advanced since the last fetch.
+	Force bool
+}
+
+type settings struct {
+	version         string
+	layout          string
+	cloneProtocol   string
+	auth            gitAuth
+	ssh             sshSettings
+	organizations   []string
+	maxParallel     int
+	shutdownTimeout time.Duration
+	cloneTimeout    time.Duration
+	updateTimeout   time.Duration
+	retry           retryPolicy
+	force           bool
+	fullRefresh     time.Duration
+	reportPath      string
+	reportRetention int
+	filter          repositoryFilter
+	lfs             bool
+	overrides       []config.RepositoryConfig
+	sources         []config.SourceConfig
+}
+
+func loadSettings(options Options) settings {
+	s := settings{
+		version:         config.GetGitVaultVersion(),
+		layout:          config.GetLayout(),
+		cloneProtocol:   config.GetCloneProtocol(),
+		organizations:   config.GetGitHubOrganizations(),
+		maxParallel:     config.GetMaxParallel(),
+		shutdownTimeout: config.GetShutdownTimeout(),
+		cloneTimeout:    config.GetCloneTimeout(),
+		updateTimeout:   config.GetUpdateTimeout(),
+		force:           options.Force,
+		fullRefresh:     config.GetFullRefreshInterval(),
+		reportPath:      config.GetReportPath(),
+		reportRetention: config.GetReportRetention(),
+		filter:          newRepositoryFilter(config.GetFilters()),
+		lfs:             config.GetLFS(),
+		overrides:       config.GetRepositoryOverrides(),
+		sources:         config.GetSources(),
+	}
+
+	ssh := config.GetSSH()
+	s.ssh = sshSettings{privateKeyPath: ssh.PrivateKeyPath, knownHosts: ssh.KnownHosts}
+
+	s.auth.token = config.GetGitHubToken()
+
+	retry := config.GetRetry()
+	s.retry = retryPolicy{
+		maxAttempts:    retry.MaxAttempts,
+		initialBackoff: retry.InitialBackoff,
+		maxBackoff:     retry.MaxBackoff,
+	}
+
+	if options.MaxParallel > 0 {
+		s.maxParallel = options.MaxParallel
+	}
+	return s
+}
+
+type mirrorTarget struct {
+	repository forge.Repository
+	directory  string
+	url        string
+	options    repositoryOptions
+	// source is the name of the configured source the repository is listed
+	// on, empty for GitHub.
+	source string
+	// upToDate is set when nothing was pushed since the last fetch, or the
+	// last fetch is more recent than the fetch interval, so an existing
+	// mirror does not need to be fetched.
+	upToDate bool
+}
+
 func getBackupDirectory() string {
 	if dir := os.Getenv("GITVAULT_BACKUP_DIR"); dir != "" {
 		return dir
@@ -26,9 +126,60 @@ func getBackupDirectory() string {
 	return defaultBackupDirectory
 }
 
-func getGithubRepositories() ([]github.Repository, error) {
+func getGithubRepositories(ctx context.Context) ([]forge.Repository, error) {
 	client := github.NewClient()
-	return client.GetUserRepos()
+	return client.GetRepos(ctx)
+}
+
+// getOrganizationRepositories lists the repositories of every configured
+// organization, keyed by organization login.
+func getOrganizationRepositories(ctx context.Context) (map[string][]forge.Repository, error) {
+	client := github.NewClient()
+	repositories := make(map[string][]forge.Repository)
+	for _, organization := range config.GetGitHubOrganizations() {
+		repos, err := client.GetOrgRepos(ctx, organization)
+		if err != nil {
+			return nil, fmt.Errorf("organization %s: %w", organization, err)
+		}
+		repositories[organization] = repos
+	}
+	return repositories, nil
+}
+
+// newProvider returns the client for a configured source.
+func newProvider(source config.SourceConfig) (forge.Provider, error) {
+	switch source.Type {
+	case config.SourceTypeGitLab:
+		return gitlab.NewClient(source), nil
+	case config.SourceTypeGitea:
+		return gitea.NewClient(source), nil
+	case config.SourceTypeBitbucket:
+		return bitbucket.NewCloudClient(source), nil
+	case config.SourceTypeBitbucketServer:
+		return bitbucket.NewServerClient(source), nil
+	case config.SourceTypeStatic:
+		return static.NewSource(source), nil
+	}
+	return nil, fmt.Errorf("source %s has unsupported type %q", source.Name, source.Type)
+}
+
+// getSourceRepositories lists the repositories of every configured source,
+// keyed by source name.
+func getSourceRepositories(ctx context.Context) (map[string][]forge.Repository, error) {
+	repositories := make(map[string][]forge.Repository)
+	for _, source := range config.GetSources() {
+		provider, err := newProvider(source)
+		if err != nil {
+			return nil, err
+		}
+
+		repos, err := provider.ListRepositories(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", source.Name, err)
+		}
+		repositories[source.Name] = repos
+	}
+	return repositories, nil
 }
 
 func repositoryName(fullName string) string {
@@ -39,58 +190,180 @@ func repositoryName(fullName string) string {
 	return fullName
 }
 
-func gitCloneMirror(sshURL, targetDirectory string) error {
-	cmd := exec.Command("git", "clone", "--mirror", sshURL, targetDirectory)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
+// listTargets fetches every repository to mirror from GitHub and the other
+// sources and resolves where each one is mirrored.
+func listTargets(ctx context.Context, dir string, settings settings) ([]mirrorTarget, error) {
+	repos, err := fetchGithubRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from GitHub: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("fetched %d repositories from GitHub", len(repos)))
+
+	orgRepos, err := fetchOrganizationRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organization repositories from GitHub: %w", err)
+	}
+
+	for organization, repositories := range orgRepos {
+		slog.Info(fmt.Sprintf("fetched %d repositories from GitHub organization %s", len(repositories), organization))
+	}
+
+	sourceRepos, err := fetchSourceRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from sources: %w", err)
+	}
+
+	for source, repositories := range sourceRepos {
+		slog.Info(fmt.Sprintf("fetched %d repositories from source %s", len(repositories), source))
+	}
 
-func gitRemoteUpdate(repository string) error {
-	cmd := exec.Command("git", "remote", "update")
-	cmd.Dir = repository
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return mirrorTargets(dir, settings, repos, orgRepos, sourceRepos), nil
 }
 
-func run(dir string) error {
+func run(ctx context.Context, dir string, settings settings) (*SyncResult, error) {
 	if dir != "." {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create backup directory %s: %w", dir, err)
+			return nil, fmt.Errorf("failed to create backup directory %s: %w", dir, err)
 		}
 	}
 
-	repos, err := fetchGithubRepositories()
+	sshCommand, err := prepareSSH(settings.ssh)
 	if err != nil {
-		return fmt.Errorf("failed to fetch repositories from GitHub: %w", err)
+		return nil, fmt.Errorf("failed to prepare ssh: %w", err)
 	}
+	settings.auth.sshCommand = sshCommand
 
-	slog.Info(fmt.Sprintf("fetched %d repositories from GitHub", len(repos)))
+	targets, err := listTargets(ctx, dir, settings)
+	if err != nil {
+		return nil, err
+	}
+	targets, filtered := filterTargets(targets, settings.filter)
+	removeStaleStagingDirectories(targets)
+	migrateLegacyMirrors(dir, targets)
 
-	for _, repository := range repos {
-		name := repositoryName(repository.FullName)
-		repositoryDirectory := filepath.Join(dir, name+".git")
+	inv, err := loadInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := inv.reconcile(dir, settings, targets, filtered); err != nil {
+		return nil, err
+	}
+
+	for index := range targets {
+		targets[index].upToDate = inv.skipFetch(targets[index], settings) != ""
+	}
 
-		if info, err := os.Stat(repositoryDirectory); err == nil && info.IsDir() {
-			slog.Info("updating mirror", "repository", repository.FullName, "dir", repositoryDirectory)
-			if err := remoteUpdateFn(repositoryDirectory); err != nil {
-				slog.Error("failed to update mirror", "repo", repository.FullName, "error", err)
-				continue
+	result := &SyncResult{}
+	var journal []db.RefUpdate
+	for _, mirrored := range mirrorAll(ctx, targets, settings) {
+		fullName := mirrored.target.repository.FullName
+		for _, update := range mirrored.updates {
+			update.Source = mirrored.target.source
+			update.ID = mirrored.target.repository.ID
+			update.Repository = fullName
+			update.At = now()
+			journal = append(journal, update)
+		}
+		repository := RepositoryResult{
+			FullName:         fullName,
+			Directory:        mirrored.target.directory,
+			Duration:         mirrored.duration,
+			BytesTransferred: mirrored.bytes,
+			PreservedRefs:    mirrored.preserved,
+		}
+
+		switch {
+		case mirrored.skipped:
+			repository.Outcome = OutcomeSkipped
+		case mirrored.err != nil:
+			repository.Outcome = OutcomeFailed
+			repository.Err = mirrored.err
+			inv.recordFailure(fullName, mirrored.err)
+		default:
+			record := inv.get(fullName)
+			repository.Outcome = outcome(mirrored, record)
+			if record != nil && mirrored.refs != nil {
+				repository.RefChanges = refChanges(record.Refs, mirrored.refs)
 			}
-		} else {
-			slog.Info("cloning mirror", "repository", repository.FullName, "dir", repositoryDirectory)
-			if err := cloneMirrorFn(repository.SSHURL, repositoryDirectory); err != nil {
-				slog.Error("failed to clone mirror", "repository", repository.FullName, "error", err)
-				continue
+			inv.recordSuccess(fullName, mirrored.refs)
+			if mirrored.action != actionSkipUnchanged {
+				inv.recordFetch(mirrored.target.repository)
 			}
 		}
+		result.add(repository)
+	}
+
+	for _, skipped := range filtered {
+		slog.Info("repository filtered out", "repository", skipped.target.repository.FullName, "rule", skipped.rule)
+		result.add(RepositoryResult{
+			FullName:  skipped.target.repository.FullName,
+			Directory: skipped.target.directory,
+			Outcome:   OutcomeFiltered,
+			Reason:    skipReason(skipped.rule),
+		})
+	}
+
+	if err := inv.save(); err != nil {
+		return result, err
+	}
+
+	if err := db.AppendJournal(journal); err != nil {
+		return result, fmt.Errorf("failed to write ref journal: %w", err)
 	}
 
-	slog.Info("sync completed successfully", "total", len(repos))
-	return nil
+	var errs []error
+	if skipped := result.Count(OutcomeSkipped); skipped > 0 {
+		slog.Warn("sync interrupted, repositories were skipped", "skipped", skipped)
+		errs = append(errs, fmt.Errorf("sync interrupted, %d repositories skipped: %w", skipped, ctx.Err()))
+	}
+	if failed := result.Count(OutcomeFailed); failed > 0 {
+		slog.Error("sync completed with failures", "failed", failed, "total", len(targets))
+		errs = append(errs, fmt.Errorf("%w: %d of %d", ErrRepositoriesFailed, failed, len(targets)))
+	}
+	if len(errs) > 0 {
+		return result, errors.Join(errs...)
+	}
+
+	slog.Info("sync completed successfully", "total", len(targets))
+	return result, nil
 }
 
-func Run() error {
-	return run(getBackupDirectory())
+// outcome tells a clone from an update, and an update that fetched new
+// objects from one that found the mirror already up to date.
+func outcome(mirrored mirrorResult, record *db.Repository) Outcome {
+	switch mirrored.action {
+	case actionClone:
+		return OutcomeCloned
+	case actionSkipUnchanged:
+		return OutcomeUnchanged
+	}
+	if record != nil && record.Refs != nil && mirrored.refs != nil && maps.Equal(record.Refs, mirrored.refs) {
+		return OutcomeUnchanged
+	}
+	return OutcomeUpdated
+}
+
+// runAndReport runs a sync and writes its report, including for runs that
+// failed before any repository was mirrored.
+func runAndReport(ctx context.Context, dir string, settings settings) (*SyncResult, error) {
+	startedAt := now()
+	result, err := run(ctx, dir, settings)
+
+	report := newReport(startedAt, now(), settings.version, result, err)
+	if reportErr := saveReport(report, settings.reportPath, settings.reportRetention); reportErr != nil {
+		slog.Error("failed to write report", "error", reportErr)
+	}
+
+	return result, err
+}
+
+// Run mirrors every listed repository and reports the outcome of each. The
+// error wraps ErrRepositoriesFailed when any repository could not be synced.
+func Run(ctx context.Context, options Options) (*SyncResult, error) {
+	if _, err := config.Get(); err != nil {
+		return nil, err
+	}
+	return runAndReport(ctx, getBackupDirectory(), loadSettings(options))
 }
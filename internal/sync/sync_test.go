@@ -1,17 +1,28 @@
 package sync
 
 import (
+	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	gosync "sync"
 	"testing"
 
-	"github.com/konkasidiaris/gitvault/internal/github"
+	"github.com/konkasidiaris/gitvault/internal/bitbucket"
+	"github.com/konkasidiaris/gitvault/internal/config"
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/konkasidiaris/gitvault/internal/gitea"
+	"github.com/konkasidiaris/gitvault/internal/gitlab"
+	"github.com/konkasidiaris/gitvault/internal/static"
 	"github.com/stretchr/testify/assert"
 )
 
-// mockGitOps stores calls made to clone/update for assertions.
+// mockGitOps stores calls made to clone/update for assertions. Calls may
+// arrive from several workers at once.
 type mockGitOps struct {
+	mu                     gosync.Mutex
 	cloneCalls             []cloneCall
 	updateCalls            []string
 	cloneErr               error
@@ -19,11 +30,42 @@ type mockGitOps struct {
 	cloneErrForRepository  map[string]error
 	updateErrForRepository map[string]error
 	createDirectoryOnClone bool
+	remoteURLs             map[string]string
+	refs                   map[string]map[string]string
+	setRemoteURLCalls      []cloneCall
+	verifyErr              error
+	refsAfterUpdate        map[string]map[string]string
+	fastForwards           map[string]bool
+	updateRefCalls         []updateRefCall
+	updateRefErr           error
+	fetchRefspecsCalls     []fetchRefspecsCall
+	lfsFetchCalls          []string
+	lfsFetchErr            error
+}
+
+type fetchRefspecsCall struct {
+	directory string
+	refspecs  []string
+	auth      gitAuth
+}
+
+type updateRefCall struct {
+	directory string
+	ref       string
+	object    string
 }
 
 type cloneCall struct {
-	sshURL          string
-	targetDirectory string
+	sshURL           string
+	targetDirectory  string
+	stagingDirectory string
+	auth             gitAuth
+}
+
+// stagedMirrorDirectory maps a staging directory back to the mirror it will
+// be moved to, so assertions can be written against final locations.
+func stagedMirrorDirectory(staging string) string {
+	return filepath.Join(filepath.Dir(staging), strings.TrimPrefix(filepath.Base(staging), stagingPrefix))
 }
 
 func newMockGitOps() *mockGitOps {
@@ -31,11 +73,19 @@ func newMockGitOps() *mockGitOps {
 		cloneErrForRepository:  make(map[string]error),
 		updateErrForRepository: make(map[string]error),
 		createDirectoryOnClone: true,
+		remoteURLs:             make(map[string]string),
+		refs:                   make(map[string]map[string]string),
+		refsAfterUpdate:        make(map[string]map[string]string),
+		fastForwards:           make(map[string]bool),
 	}
 }
 
-func (m *mockGitOps) clone(sshURL, targetDirectory string) error {
-	m.cloneCalls = append(m.cloneCalls, cloneCall{sshURL, targetDirectory})
+func (m *mockGitOps) clone(ctx context.Context, sshURL, stagingDirectory string, auth gitAuth, output io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	targetDirectory := stagedMirrorDirectory(stagingDirectory)
+	m.cloneCalls = append(m.cloneCalls, cloneCall{sshURL, targetDirectory, stagingDirectory, auth})
 
 	if err, ok := m.cloneErrForRepository[targetDirectory]; ok {
 		return err
@@ -46,40 +96,171 @@ func (m *mockGitOps) clone(sshURL, targetDirectory string) error {
 
 	// Simulate git clone by creating the directory
 	if m.createDirectoryOnClone {
-		return os.MkdirAll(targetDirectory, 0755)
+		return os.MkdirAll(stagingDirectory, 0755)
 	}
 	return nil
 }
 
-func (m *mockGitOps) update(repoDir string) error {
+func (m *mockGitOps) verify(ctx context.Context, repoDir string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.verifyErr
+}
+
+func (m *mockGitOps) update(ctx context.Context, repoDir string, auth gitAuth, output io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.updateCalls = append(m.updateCalls, repoDir)
 
 	if err, ok := m.updateErrForRepository[repoDir]; ok {
 		return err
 	}
-	return m.updateErr
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	if refs, ok := m.refsAfterUpdate[repoDir]; ok {
+		m.refs[repoDir] = refs
+	}
+	return nil
+}
+
+// isAncestor treats a ref move as a fast-forward only when fastForwards has
+// an "old..new" entry for it.
+func (m *mockGitOps) isAncestor(ctx context.Context, repoDir, ancestor, descendant string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.fastForwards[ancestor+".."+descendant], nil
 }
 
-func setupMocks(t *testing.T, repos []github.Repository, fetchErr error, ops *mockGitOps) {
+func (m *mockGitOps) fetchRefspecs(ctx context.Context, repoDir string, refspecs []string, auth gitAuth, output io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fetchRefspecsCalls = append(m.fetchRefspecsCalls, fetchRefspecsCall{repoDir, refspecs, auth})
+	return nil
+}
+
+func (m *mockGitOps) lfsFetch(ctx context.Context, repoDir string, auth gitAuth, output io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lfsFetchCalls = append(m.lfsFetchCalls, repoDir)
+	return m.lfsFetchErr
+}
+
+func (m *mockGitOps) updateRef(ctx context.Context, repoDir, ref, object string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.updateRefCalls = append(m.updateRefCalls, updateRefCall{repoDir, ref, object})
+	return m.updateRefErr
+}
+
+func (m *mockGitOps) listRefs(ctx context.Context, repoDir string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.refs[repoDir], nil
+}
+
+func (m *mockGitOps) remoteURL(repoDir string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	url, ok := m.remoteURLs[repoDir]
+	if !ok {
+		return "", errors.New("no remote configured")
+	}
+	return url, nil
+}
+
+func (m *mockGitOps) setRemoteURL(repoDir, url string, output io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setRemoteURLCalls = append(m.setRemoteURLCalls, cloneCall{sshURL: url, targetDirectory: repoDir})
+	return nil
+}
+
+func testSettings() settings {
+	return settings{layout: config.LayoutFlat}
+}
+
+func setupMocks(t *testing.T, repos []forge.Repository, fetchErr error, ops *mockGitOps) {
 	t.Helper()
 
+	t.Setenv("GITVAULT_LOCKFILE_PATH", filepath.Join(t.TempDir(), "gitvault.lock.json"))
+
 	originalFetch := fetchGithubRepositories
+	originalFetchOrganizations := fetchOrganizationRepositories
+	originalFetchSources := fetchSourceRepositories
 	originalClone := cloneMirrorFn
 	originalUpdate := remoteUpdateFn
-
-	fetchGithubRepositories = func() ([]github.Repository, error) {
+	originalRemoteURL := remoteURLFn
+	originalListRefs := listRefsFn
+	originalSetRemoteURL := setRemoteURLFn
+	originalVerify := verifyMirrorFn
+	originalIsAncestor := isAncestorFn
+	originalUpdateRef := updateRefFn
+	originalFetchRefspecs := fetchRefspecsFn
+	originalLFSFetch := lfsFetchFn
+
+	fetchGithubRepositories = func(ctx context.Context) ([]forge.Repository, error) {
 		return repos, fetchErr
 	}
+	fetchOrganizationRepositories = func(ctx context.Context) (map[string][]forge.Repository, error) {
+		return nil, nil
+	}
+	fetchSourceRepositories = func(ctx context.Context) (map[string][]forge.Repository, error) {
+		return nil, nil
+	}
 	cloneMirrorFn = ops.clone
 	remoteUpdateFn = ops.update
+	remoteURLFn = ops.remoteURL
+	listRefsFn = ops.listRefs
+	setRemoteURLFn = ops.setRemoteURL
+	verifyMirrorFn = ops.verify
+	isAncestorFn = ops.isAncestor
+	updateRefFn = ops.updateRef
+	fetchRefspecsFn = ops.fetchRefspecs
+	lfsFetchFn = ops.lfsFetch
 
 	t.Cleanup(func() {
 		fetchGithubRepositories = originalFetch
+		fetchOrganizationRepositories = originalFetchOrganizations
+		fetchSourceRepositories = originalFetchSources
 		cloneMirrorFn = originalClone
 		remoteUpdateFn = originalUpdate
+		remoteURLFn = originalRemoteURL
+		listRefsFn = originalListRefs
+		setRemoteURLFn = originalSetRemoteURL
+		verifyMirrorFn = originalVerify
+		isAncestorFn = originalIsAncestor
+		updateRefFn = originalUpdateRef
+		fetchRefspecsFn = originalFetchRefspecs
+		lfsFetchFn = originalLFSFetch
 	})
 }
 
+func mockOrganizations(t *testing.T, orgRepos map[string][]forge.Repository, fetchErr error) {
+	t.Helper()
+
+	fetchOrganizationRepositories = func(ctx context.Context) (map[string][]forge.Repository, error) {
+		return orgRepos, fetchErr
+	}
+}
+
+func mockSources(t *testing.T, sourceRepos map[string][]forge.Repository, fetchErr error) {
+	t.Helper()
+
+	fetchSourceRepositories = func(ctx context.Context) (map[string][]forge.Repository, error) {
+		return sourceRepos, fetchErr
+	}
+}
+
 func TestRepoName_Success(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -106,7 +287,7 @@ func TestRun_FetchError(t *testing.T) {
 	ops := newMockGitOps()
 	setupMocks(t, nil, errors.New("API error"), ops)
 
-	err := run(t.TempDir())
+	_, err := run(context.Background(), t.TempDir(), testSettings())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to fetch repositories from GitHub")
@@ -116,9 +297,9 @@ func TestRun_FetchError(t *testing.T) {
 
 func TestRun_EmptyRepos(t *testing.T) {
 	ops := newMockGitOps()
-	setupMocks(t, []github.Repository{}, nil, ops)
+	setupMocks(t, []forge.Repository{}, nil, ops)
 
-	err := run(t.TempDir())
+	_, err := run(context.Background(), t.TempDir(), testSettings())
 
 	assert.NoError(t, err)
 	assert.Empty(t, ops.cloneCalls)
@@ -127,7 +308,7 @@ func TestRun_EmptyRepos(t *testing.T) {
 
 func TestRun_ClonesNewRepos(t *testing.T) {
 	dir := t.TempDir()
-	repos := []github.Repository{
+	repos := []forge.Repository{
 		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
 		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
 	}
@@ -135,7 +316,7 @@ func TestRun_ClonesNewRepos(t *testing.T) {
 	ops := newMockGitOps()
 	setupMocks(t, repos, nil, ops)
 
-	err := run(dir)
+	_, err := run(context.Background(), dir, testSettings())
 
 	assert.NoError(t, err)
 	assert.Len(t, ops.cloneCalls, 2)
@@ -152,7 +333,7 @@ func TestRun_UpdatesExistingMirrors(t *testing.T) {
 	os.MkdirAll(filepath.Join(dir, "repo1.git"), 0755)
 	os.MkdirAll(filepath.Join(dir, "repo2.git"), 0755)
 
-	repos := []github.Repository{
+	repos := []forge.Repository{
 		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
 		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
 	}
@@ -160,7 +341,7 @@ func TestRun_UpdatesExistingMirrors(t *testing.T) {
 	ops := newMockGitOps()
 	setupMocks(t, repos, nil, ops)
 
-	err := run(dir)
+	_, err := run(context.Background(), dir, testSettings())
 
 	assert.NoError(t, err)
 	assert.Empty(t, ops.cloneCalls)
@@ -174,7 +355,7 @@ func TestRun_MixedCloneAndUpdate(t *testing.T) {
 
 	os.MkdirAll(filepath.Join(dir, "repo1.git"), 0755)
 
-	repos := []github.Repository{
+	repos := []forge.Repository{
 		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
 		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
 	}
@@ -182,7 +363,7 @@ func TestRun_MixedCloneAndUpdate(t *testing.T) {
 	ops := newMockGitOps()
 	setupMocks(t, repos, nil, ops)
 
-	err := run(dir)
+	_, err := run(context.Background(), dir, testSettings())
 
 	assert.NoError(t, err)
 	assert.Len(t, ops.updateCalls, 1)
@@ -195,7 +376,7 @@ func TestRun_MixedCloneAndUpdate(t *testing.T) {
 func TestRun_CloneErrorContinues(t *testing.T) {
 	dir := t.TempDir()
 
-	repos := []github.Repository{
+	repos := []forge.Repository{
 		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
 		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
 	}
@@ -204,9 +385,12 @@ func TestRun_CloneErrorContinues(t *testing.T) {
 	ops.cloneErrForRepository[filepath.Join(dir, "repo1.git")] = errors.New("clone failed")
 	setupMocks(t, repos, nil, ops)
 
-	err := run(dir)
+	result, err := run(context.Background(), dir, testSettings())
 
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	assert.EqualError(t, err, "repositories failed to sync: 1 of 2")
+	assert.Equal(t, 1, result.Count(OutcomeFailed))
+	assert.Equal(t, 1, result.Count(OutcomeCloned))
 	assert.Len(t, ops.cloneCalls, 2)
 }
 
@@ -216,7 +400,7 @@ func TestRun_UpdateErrorContinues(t *testing.T) {
 	os.MkdirAll(filepath.Join(dir, "repo1.git"), 0755)
 	os.MkdirAll(filepath.Join(dir, "repo2.git"), 0755)
 
-	repos := []github.Repository{
+	repos := []forge.Repository{
 		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
 		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
 	}
@@ -225,9 +409,14 @@ func TestRun_UpdateErrorContinues(t *testing.T) {
 	ops.updateErrForRepository[filepath.Join(dir, "repo1.git")] = errors.New("update failed")
 	setupMocks(t, repos, nil, ops)
 
-	err := run(dir)
+	result, err := run(context.Background(), dir, testSettings())
 
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	failed := result.Failed()
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "user/repo1", failed[0].FullName)
+	assert.EqualError(t, failed[0].Err, "update failed")
+	assert.Equal(t, 1, result.Count(OutcomeUpdated))
 	assert.Len(t, ops.updateCalls, 2)
 }
 
@@ -236,9 +425,9 @@ func TestRun_CreatesBackupDir(t *testing.T) {
 	dir := filepath.Join(parent, "nested", "backup")
 
 	ops := newMockGitOps()
-	setupMocks(t, []github.Repository{}, nil, ops)
+	setupMocks(t, []forge.Repository{}, nil, ops)
 
-	err := run(dir)
+	_, err := run(context.Background(), dir, testSettings())
 
 	assert.NoError(t, err)
 	info, statErr := os.Stat(dir)
@@ -249,7 +438,7 @@ func TestRun_CreatesBackupDir(t *testing.T) {
 func TestRun_AllClonesFail(t *testing.T) {
 	dir := t.TempDir()
 
-	repos := []github.Repository{
+	repos := []forge.Repository{
 		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
 		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
 	}
@@ -258,9 +447,10 @@ func TestRun_AllClonesFail(t *testing.T) {
 	ops.cloneErr = errors.New("clone failed")
 	setupMocks(t, repos, nil, ops)
 
-	err := run(dir)
+	result, err := run(context.Background(), dir, testSettings())
 
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	assert.Equal(t, 2, result.Count(OutcomeFailed))
 	assert.Len(t, ops.cloneCalls, 2)
 }
 
@@ -270,16 +460,279 @@ func TestRun_FileExistsButNotDir(t *testing.T) {
 	filePath := filepath.Join(dir, "repo1.git")
 	os.WriteFile(filePath, []byte("not a dir"), 0644)
 
-	repos := []github.Repository{
+	repos := []forge.Repository{
 		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
 	}
 
 	ops := newMockGitOps()
 	setupMocks(t, repos, nil, ops)
 
-	err := run(dir)
+	_, err := run(context.Background(), dir, testSettings())
+
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	assert.Len(t, ops.cloneCalls, 1)
+	assert.Empty(t, ops.updateCalls)
+}
+
+func TestRun_ClonesOrganizationReposIntoSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git"},
+	}
+	orgRepos := map[string][]forge.Repository{
+		"acme": {
+			{ID: 2, FullName: "acme/tools", SSHURL: "git@github.com:acme/tools.git"},
+		},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	mockOrganizations(t, orgRepos, nil)
+
+	_, err := run(context.Background(), dir, testSettings())
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, 2)
+	assert.Equal(t, filepath.Join(dir, "tools.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, "git@github.com:acme/tools.git", ops.cloneCalls[1].sshURL)
+	assert.Equal(t, filepath.Join(dir, "acme", "tools.git"), ops.cloneCalls[1].targetDirectory)
+}
+
+func TestRun_OrganizationRepoListedForUserIsMirroredOnce(t *testing.T) {
+	dir := t.TempDir()
+	shared := forge.Repository{ID: 2, FullName: "acme/shared", SSHURL: "git@github.com:acme/shared.git"}
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+		shared,
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	mockOrganizations(t, map[string][]forge.Repository{"acme": {shared}}, nil)
+
+	_, err := run(context.Background(), dir, testSettings())
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, 2)
+	assert.Equal(t, filepath.Join(dir, "repo1.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, filepath.Join(dir, "acme", "shared.git"), ops.cloneCalls[1].targetDirectory)
+}
+
+func TestRun_OrganizationFetchError(t *testing.T) {
+	ops := newMockGitOps()
+	setupMocks(t, []forge.Repository{{ID: 1, FullName: "user/repo1"}}, nil, ops)
+	mockOrganizations(t, nil, errors.New("API error"))
+
+	_, err := run(context.Background(), t.TempDir(), testSettings())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to fetch organization repositories from GitHub")
+	assert.Empty(t, ops.cloneCalls)
+}
+
+func TestRun_ClonesSourceReposIntoSourceDirectory(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/tools", SSHURL: "git@github.com:user/tools.git"},
+	}
+	sourceRepos := map[string][]forge.Repository{
+		"work": {
+			{ID: 1, FullName: "platform/tools", SSHURL: "git@gitlab.example.com:platform/tools.git", CloneURL: "https://gitlab.example.com/platform/tools.git"},
+		},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	mockSources(t, sourceRepos, nil)
+
+	s := testSettings()
+	s.auth = gitAuth{token: "github-token"}
+	s.sources = []config.SourceConfig{{Name: "work", Type: config.SourceTypeGitLab, Token: "glpat-token"}}
+	s.overrides = []config.RepositoryConfig{{Pattern: "work/*/*", CloneProtocol: config.CloneProtocolHTTPS}}
+	result, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, 2)
+	assert.Equal(t, "https://gitlab.example.com/platform/tools.git", ops.cloneCalls[1].sshURL)
+	assert.Equal(t, filepath.Join(dir, "work", "platform", "tools.git"), ops.cloneCalls[1].targetDirectory)
+	assert.Equal(t, gitAuth{token: "glpat-token"}, ops.cloneCalls[1].auth)
+	assert.Equal(t, "work/platform/tools", result.Repositories[1].FullName)
+
+	record := storedRepository(t, "work/platform/tools")
+	assert.Equal(t, "work", record.Source)
+	assert.Equal(t, int64(1), record.ID)
+	assert.Equal(t, filepath.Join("work", "platform", "tools.git"), record.Directory)
+	assert.Equal(t, "", storedRepository(t, "user/tools").Source)
+}
+
+func TestRun_ClonesBitbucketReposWithUsername(t *testing.T) {
+	dir := t.TempDir()
+	sourceRepos := map[string][]forge.Repository{
+		"bitbucket": {
+			{ID: 5, FullName: "acme/api", SSHURL: "git@bitbucket.org:acme/api.git", CloneURL: "https://bitbucket.org/acme/api.git"},
+		},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	mockSources(t, sourceRepos, nil)
+
+	s := testSettings()
+	s.cloneProtocol = config.CloneProtocolHTTPS
+	s.auth = gitAuth{token: "github-token"}
+	s.sources = []config.SourceConfig{{Name: "bitbucket", Type: config.SourceTypeBitbucket, Username: "alice", Token: "app-password"}}
+	_, err := run(context.Background(), dir, s)
 
 	assert.NoError(t, err)
 	assert.Len(t, ops.cloneCalls, 1)
+	assert.Equal(t, filepath.Join(dir, "bitbucket", "acme", "api.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, gitAuth{username: "alice", token: "app-password"}, ops.cloneCalls[0].auth)
+}
+
+func TestRun_MirrorsStaticSourceWithoutGitHubToken(t *testing.T) {
+	dir := t.TempDir()
+	sourceRepos := map[string][]forge.Repository{
+		"upstream": {
+			{ID: 9, FullName: "torvalds/linux", SSHURL: "https://github.com/torvalds/linux.git", CloneURL: "https://github.com/torvalds/linux.git"},
+		},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	mockSources(t, sourceRepos, nil)
+
+	s := testSettings()
+	s.cloneProtocol = config.CloneProtocolHTTPS
+	s.auth = gitAuth{token: "github-token"}
+	s.sources = []config.SourceConfig{{Name: "upstream", Type: config.SourceTypeStatic, Repositories: []config.StaticRepository{{URL: "https://github.com/torvalds/linux.git"}}}}
+	result, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, 1)
+	assert.Equal(t, "https://github.com/torvalds/linux.git", ops.cloneCalls[0].sshURL)
+	assert.Equal(t, filepath.Join(dir, "upstream", "torvalds", "linux.git"), ops.cloneCalls[0].targetDirectory)
+	assert.Equal(t, gitAuth{}, ops.cloneCalls[0].auth)
+	assert.Equal(t, OutcomeCloned, result.Repositories[0].Outcome)
+	assert.Equal(t, "upstream", storedRepository(t, "upstream/torvalds/linux").Source)
+}
+
+func TestRun_SourceFetchError(t *testing.T) {
+	ops := newMockGitOps()
+	setupMocks(t, []forge.Repository{{ID: 1, FullName: "user/repo1"}}, nil, ops)
+	mockSources(t, nil, errors.New("source work: unexpected status code: 401"))
+
+	_, err := run(context.Background(), t.TempDir(), testSettings())
+
+	assert.ErrorContains(t, err, "failed to fetch repositories from sources: source work: unexpected status code: 401")
+	assert.Empty(t, ops.cloneCalls)
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := newProvider(config.SourceConfig{Name: "gitlab", Type: config.SourceTypeGitLab, BaseURL: "https://gitlab.com"})
+	assert.NoError(t, err)
+	assert.IsType(t, &gitlab.Client{}, provider)
+
+	provider, err = newProvider(config.SourceConfig{Name: "homelab", Type: config.SourceTypeGitea, BaseURL: "http://forgejo.lan:3000"})
+	assert.NoError(t, err)
+	assert.IsType(t, &gitea.Client{}, provider)
+
+	provider, err = newProvider(config.SourceConfig{Name: "bitbucket", Type: config.SourceTypeBitbucket, BaseURL: "https://api.bitbucket.org"})
+	assert.NoError(t, err)
+	assert.IsType(t, &bitbucket.CloudClient{}, provider)
+
+	provider, err = newProvider(config.SourceConfig{Name: "datacenter", Type: config.SourceTypeBitbucketServer, BaseURL: "https://bitbucket.example.com"})
+	assert.NoError(t, err)
+	assert.IsType(t, &bitbucket.ServerClient{}, provider)
+
+	provider, err = newProvider(config.SourceConfig{Name: "upstream", Type: config.SourceTypeStatic, File: "/config/upstream.txt"})
+	assert.NoError(t, err)
+	assert.IsType(t, &static.Source{}, provider)
+
+	_, err = newProvider(config.SourceConfig{Name: "svn", Type: "svn"})
+	assert.EqualError(t, err, `source svn has unsupported type "svn"`)
+}
+
+func TestRun_HTTPSProtocolClonesWithToken(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git", CloneURL: "https://github.com/user/repo1.git"},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	s := testSettings()
+	s.cloneProtocol = config.CloneProtocolHTTPS
+	s.auth = gitAuth{token: "test-token"}
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/user/repo1.git", ops.cloneCalls[0].sshURL)
+	assert.Equal(t, gitAuth{token: "test-token"}, ops.cloneCalls[0].auth)
+	assert.Equal(t, "https://github.com/user/repo1.git", storedRepository(t, "user/repo1").CloneURL)
+}
+
+func TestRun_SwitchesRemoteWhenProtocolChanges(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "repo1.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "repo2.git"), 0755)
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git", CloneURL: "https://github.com/user/repo1.git"},
+		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git", CloneURL: "https://github.com/user/repo2.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.remoteURLs[filepath.Join(dir, "repo1.git")] = "git@github.com:user/repo1.git"
+	ops.remoteURLs[filepath.Join(dir, "repo2.git")] = "https://github.com/user/repo2.git"
+	setupMocks(t, repos, nil, ops)
+
+	s := testSettings()
+	s.cloneProtocol = config.CloneProtocolHTTPS
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []cloneCall{{sshURL: "https://github.com/user/repo1.git", targetDirectory: filepath.Join(dir, "repo1.git")}}, ops.setRemoteURLCalls)
+	assert.Len(t, ops.updateCalls, 2)
+}
+
+func TestRun_FailsRepositoryWhoseMirrorBelongsToAnotherRemote(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "tools.git"), 0755)
+	repos := []forge.Repository{
+		{ID: 1, FullName: "alice/tools", SSHURL: "git@github.com:alice/tools.git", CloneURL: "https://github.com/alice/tools.git"},
+	}
+
+	ops := newMockGitOps()
+	ops.remoteURLs[filepath.Join(dir, "tools.git")] = "git@github.com:acme/tools.git"
+	setupMocks(t, repos, nil, ops)
+
+	result, err := run(context.Background(), dir, testSettings())
+
+	assert.ErrorIs(t, err, ErrRepositoriesFailed)
+	assert.Equal(t, OutcomeFailed, result.Repositories[0].Outcome)
+	assert.EqualError(t, result.Repositories[0].Err, "mirror at "+filepath.Join(dir, "tools.git")+" belongs to git@github.com:acme/tools.git, not alice/tools")
+	assert.Empty(t, ops.setRemoteURLCalls)
 	assert.Empty(t, ops.updateCalls)
 }
+
+// useInvalidConfig points the configuration at a file that fails
+// validation. The configuration is only loaded once per process, so every
+// test using it must expect the same error.
+func useInvalidConfig(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"github_token": "test-token", "github_username": "test-user", "clone_protocol": "ftp"}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITVAULT_CONFIG_PATH", path)
+}
+
+func TestRun_InvalidConfigReturnsError(t *testing.T) {
+	useInvalidConfig(t)
+
+	result, err := Run(context.Background(), Options{})
+
+	assert.ErrorContains(t, err, "[Config]")
+	assert.Nil(t, result)
+}
@@ -0,0 +1,276 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/db"
+)
+
+var mirrorSizeFn = mirrorSize
+
+const (
+	actionClone         = "clone"
+	actionUpdate        = "update"
+	actionSkipUnchanged = "skip-unchanged"
+)
+
+type mirrorResult struct {
+	target    mirrorTarget
+	action    string
+	refs      map[string]string
+	skipped   bool
+	err       error
+	duration  time.Duration
+	bytes     int64
+	updates   []db.RefUpdate
+	preserved []PreservedRef
+}
+
+// gracefulContext returns the context git processes run under. It outlives
+// ctx by timeout, so in-flight repositories get a chance to finish after a
+// shutdown was requested before they are killed.
+func gracefulContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	gitCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		slog.Warn("shutdown requested, waiting for in-flight repositories", "timeout", timeout)
+		time.AfterFunc(timeout, cancel)
+	})
+
+	return gitCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// mirrorAll clones or updates every target with at most settings.maxParallel
+// concurrent workers and returns the results in the order of targets. Each
+// mirror directory is claimed by exactly one target before any work starts,
+// so two workers never touch the same directory. Once ctx is done no new
+// repository is started and the remaining ones are reported as skipped.
+func mirrorAll(ctx context.Context, targets []mirrorTarget, settings settings) []mirrorResult {
+	maxParallel := max(settings.maxParallel, 1)
+
+	gitCtx, cancel := gracefulContext(ctx, settings.shutdownTimeout)
+	defer cancel()
+
+	results := make([]mirrorResult, len(targets))
+	claimed := make(map[string]string, len(targets))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(maxParallel, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = mirror(gitCtx, targets[index], settings)
+			}
+		}()
+	}
+
+	for index, target := range targets {
+		if owner, ok := claimed[target.directory]; ok {
+			err := fmt.Errorf("mirror directory %s is already used by %s", target.directory, owner)
+			slog.Error("skipping repository", "repository", target.repository.FullName, "error", err)
+			results[index] = mirrorResult{target: target, err: err}
+			continue
+		}
+		claimed[target.directory] = target.repository.FullName
+
+		if ctx.Err() != nil {
+			results[index] = mirrorResult{target: target, skipped: true}
+			continue
+		}
+
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			results[index] = mirrorResult{target: target, skipped: true}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// mirror clones or updates a single target, retrying transient failures.
+// Clones are staged, so one that is killed by the shutdown deadline leaves no
+// half-written mirror behind.
+func mirror(ctx context.Context, target mirrorTarget, settings settings) (result mirrorResult) {
+	repository := target.repository
+	logger := slog.With("repository", repository.FullName)
+	output := newLogWriter(logger)
+	defer output.Flush()
+
+	started := time.Now()
+	var sizeBefore int64
+	defer func() {
+		result.duration = time.Since(started)
+		if result.err == nil && !result.skipped && result.action != actionSkipUnchanged {
+			result.bytes = max(mirrorSizeFn(target.directory)-sizeBefore, 0)
+		}
+	}()
+
+	result = mirrorResult{target: target}
+	auth := target.options.auth(settings.auth)
+	var before map[string]string
+	if info, err := os.Stat(target.directory); err == nil && info.IsDir() {
+		if target.upToDate {
+			logger.Info("mirror is up to date, skipping", "pushed_at", repository.PushedAt, "fetch_interval", target.options.fetchInterval)
+			result.action = actionSkipUnchanged
+			return result
+		}
+
+		result.action = actionUpdate
+		logger.Info("updating mirror", "dir", target.directory)
+		if err := ensureRemote(logger, target, output); err != nil {
+			logger.Error("failed to switch remote of mirror", "error", err)
+			result.err = err
+			return result
+		}
+		sizeBefore = mirrorSizeFn(target.directory)
+		var err error
+		before, err = listRefsFn(ctx, target.directory)
+		if err != nil {
+			logger.Warn("failed to snapshot refs before update, rewritten refs cannot be preserved", "error", err)
+		}
+
+		err = withRetry(ctx, logger, actionUpdate, target.options.updateTimeout, settings.retry, output, func(ctx context.Context, output io.Writer) error {
+			return remoteUpdateFn(ctx, target.directory, auth, output)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				logger.Warn("update interrupted by shutdown", "error", err)
+				result.skipped = true
+				return result
+			}
+			logger.Error("failed to update mirror", "error", err)
+			result.err = err
+			return result
+		}
+	} else {
+		result.action = actionClone
+		logger.Info("cloning mirror", "dir", target.directory)
+		err := withRetry(ctx, logger, actionClone, target.options.cloneTimeout, settings.retry, output, func(ctx context.Context, output io.Writer) error {
+			return cloneAtomically(ctx, target.url, target.directory, auth, output)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				logger.Warn("clone interrupted by shutdown, rolled back", "dir", target.directory, "error", err)
+				result.skipped = true
+				return result
+			}
+			logger.Error("failed to clone mirror", "error", err)
+			result.err = err
+			return result
+		}
+	}
+
+	if err := fetchExtras(ctx, logger, target, settings, auth, output); err != nil {
+		if ctx.Err() != nil {
+			logger.Warn("fetch interrupted by shutdown", "error", err)
+			result.skipped = true
+			return result
+		}
+		logger.Error("failed to fetch mirror", "error", err)
+		result.err = err
+		return result
+	}
+
+	refs, err := listRefsFn(ctx, target.directory)
+	if err != nil {
+		logger.Warn("failed to read refs of mirror", "error", err)
+	}
+	result.refs = refs
+
+	if refs == nil || (result.action == actionUpdate && before == nil) {
+		return result
+	}
+
+	result.updates, err = classifyRefUpdates(ctx, target.directory, before, refs)
+	if err == nil {
+		result.preserved, err = preserveRewrittenRefs(ctx, logger, target.directory, result.updates)
+	}
+	if err != nil {
+		logger.Error("failed to preserve rewritten refs", "error", err)
+		result.err = err
+	}
+	return result
+}
+
+// fetchExtras fetches what a plain mirror fetch leaves out for repositories
+// that ask for it: extra refspecs and Git LFS objects.
+func fetchExtras(ctx context.Context, logger *slog.Logger, target mirrorTarget, settings settings, auth gitAuth, output io.Writer) error {
+	if refspecs := target.options.fetchRefspecs; len(refspecs) > 0 {
+		err := withRetry(ctx, logger, "fetch refspecs", target.options.updateTimeout, settings.retry, output, func(ctx context.Context, output io.Writer) error {
+			return fetchRefspecsFn(ctx, target.directory, refspecs, auth, output)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if target.options.lfs {
+		return withRetry(ctx, logger, "lfs fetch", target.options.updateTimeout, settings.retry, output, func(ctx context.Context, output io.Writer) error {
+			return lfsFetchFn(ctx, target.directory, auth, output)
+		})
+	}
+	return nil
+}
+
+// ensureRemote points an existing mirror at the URL of the configured clone
+// protocol, so switching protocols does not require cloning again. A mirror
+// whose remote is another repository is left alone and fails the repository,
+// rather than being fetched into.
+func ensureRemote(logger *slog.Logger, target mirrorTarget, output io.Writer) error {
+	if target.url == "" {
+		return nil
+	}
+
+	remoteURL, err := remoteURLFn(target.directory)
+	if err != nil {
+		logger.Warn("could not read remote of mirror", "error", err)
+		return nil
+	}
+	if remoteURL == target.url {
+		return nil
+	}
+	if !isRemoteOf(remoteURL, target.repository) {
+		return fmt.Errorf("mirror at %s belongs to %s, not %s", target.directory, remoteURL, target.repository.FullName)
+	}
+
+	logger.Info("switching remote of mirror", "from", remoteURL, "to", target.url)
+	if err := setRemoteURLFn(target.directory, target.url, output); err != nil {
+		return fmt.Errorf("failed to set remote to %s: %w", target.url, err)
+	}
+	return nil
+}
+
+// mirrorSize is the size of a mirror's object store. Its growth during a
+// clone or update approximates how many bytes were transferred: git stores
+// small fetches as loose objects, which take more space than the pack they
+// arrived in. It walks the whole store, so it is only measured when git runs.
+func mirrorSize(directory string) int64 {
+	var size int64
+	filepath.WalkDir(filepath.Join(directory, "objects"), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
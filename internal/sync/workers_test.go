@@ -0,0 +1,278 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/konkasidiaris/gitvault/internal/forge"
+	"github.com/stretchr/testify/assert"
+)
+
+func workerSettings(maxParallel int, shutdownTimeout time.Duration) settings {
+	s := testSettings()
+	s.maxParallel = maxParallel
+	s.shutdownTimeout = shutdownTimeout
+	return s
+}
+
+func TestMirrorAll_BoundsParallelism(t *testing.T) {
+	dir := t.TempDir()
+	var targets []mirrorTarget
+	for i := range 12 {
+		targets = append(targets, mirrorTarget{
+			repository: forge.Repository{ID: int64(i), FullName: fmt.Sprintf("user/repo%d", i)},
+			directory:  filepath.Join(dir, fmt.Sprintf("repo%d.git", i)),
+		})
+	}
+
+	var running, peak atomic.Int32
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	cloneMirrorFn = func(ctx context.Context, sshURL, targetDirectory string, auth gitAuth, output io.Writer) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			observed := peak.Load()
+			if current <= observed || peak.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return os.MkdirAll(targetDirectory, 0755)
+	}
+
+	results := mirrorAll(context.Background(), targets, workerSettings(3, time.Second))
+
+	assert.Len(t, results, len(targets))
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(1))
+	for i, result := range results {
+		assert.NoError(t, result.err)
+		assert.Equal(t, actionClone, result.action)
+		assert.Equal(t, targets[i], result.target)
+	}
+}
+
+func TestMirrorAll_RejectsSharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "tools.git")
+	targets := []mirrorTarget{
+		{repository: forge.Repository{ID: 1, FullName: "alice/tools"}, directory: shared},
+		{repository: forge.Repository{ID: 2, FullName: "acme/tools"}, directory: shared},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+
+	results := mirrorAll(context.Background(), targets, workerSettings(4, time.Second))
+
+	assert.NoError(t, results[0].err)
+	assert.EqualError(t, results[1].err, fmt.Sprintf("mirror directory %s is already used by alice/tools", shared))
+	assert.Len(t, ops.cloneCalls, 1)
+}
+
+func TestMirrorAll_AggregatesResults(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "existing.git"), 0755)
+	targets := []mirrorTarget{
+		{repository: forge.Repository{ID: 1, FullName: "user/existing"}, directory: filepath.Join(dir, "existing.git")},
+		{repository: forge.Repository{ID: 2, FullName: "user/new"}, directory: filepath.Join(dir, "new.git")},
+		{repository: forge.Repository{ID: 3, FullName: "user/broken"}, directory: filepath.Join(dir, "broken.git")},
+	}
+
+	ops := newMockGitOps()
+	ops.cloneErrForRepository[filepath.Join(dir, "broken.git")] = errors.New("clone failed")
+	ops.refs[filepath.Join(dir, "existing.git")] = map[string]string{"refs/heads/main": "aaaa"}
+	setupMocks(t, nil, nil, ops)
+
+	results := mirrorAll(context.Background(), targets, workerSettings(2, time.Second))
+
+	assert.Equal(t, actionUpdate, results[0].action)
+	assert.NoError(t, results[0].err)
+	assert.Equal(t, map[string]string{"refs/heads/main": "aaaa"}, results[0].refs)
+	assert.Equal(t, actionClone, results[1].action)
+	assert.NoError(t, results[1].err)
+	assert.Equal(t, actionClone, results[2].action)
+	assert.EqualError(t, results[2].err, "clone failed")
+}
+
+func TestMirrorAll_MeasuresSizeOnlyWhenGitRuns(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "quiet.git"), 0755)
+	os.MkdirAll(filepath.Join(dir, "active.git"), 0755)
+	targets := []mirrorTarget{
+		{repository: forge.Repository{ID: 1, FullName: "user/quiet"}, directory: filepath.Join(dir, "quiet.git"), upToDate: true},
+		{repository: forge.Repository{ID: 2, FullName: "user/active"}, directory: filepath.Join(dir, "active.git")},
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+
+	var measured []string
+	sizes := map[string]int64{}
+	originalMirrorSize := mirrorSizeFn
+	mirrorSizeFn = func(directory string) int64 {
+		measured = append(measured, directory)
+		sizes[directory] += 1024
+		return sizes[directory]
+	}
+	t.Cleanup(func() { mirrorSizeFn = originalMirrorSize })
+
+	results := mirrorAll(context.Background(), targets, workerSettings(1, time.Second))
+
+	assert.Equal(t, actionSkipUnchanged, results[0].action)
+	assert.Equal(t, int64(0), results[0].bytes)
+	assert.Equal(t, int64(1024), results[1].bytes)
+	assert.Equal(t, []string{filepath.Join(dir, "active.git"), filepath.Join(dir, "active.git")}, measured)
+}
+
+func TestMirrorAll_NoTargets(t *testing.T) {
+	assert.Empty(t, mirrorAll(context.Background(), nil, workerSettings(4, time.Second)))
+}
+
+func TestRun_MirrorsConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	var repos []forge.Repository
+	for i := range 8 {
+		repos = append(repos, forge.Repository{
+			ID:       int64(i),
+			FullName: fmt.Sprintf("user/repo%d", i),
+			SSHURL:   fmt.Sprintf("git@github.com:user/repo%d.git", i),
+		})
+	}
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+
+	s := testSettings()
+	s.maxParallel = 4
+	_, err := run(context.Background(), dir, s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ops.cloneCalls, len(repos))
+
+	var targets []string
+	for _, call := range ops.cloneCalls {
+		targets = append(targets, call.targetDirectory)
+	}
+	for _, repo := range repos {
+		assert.Contains(t, targets, filepath.Join(dir, repositoryName(repo.FullName)+".git"))
+	}
+}
+
+func TestMirrorAll_SkipsRemainingRepositoriesAfterCancel(t *testing.T) {
+	dir := t.TempDir()
+	var targets []mirrorTarget
+	for i := range 5 {
+		targets = append(targets, mirrorTarget{
+			repository: forge.Repository{ID: int64(i), FullName: fmt.Sprintf("user/repo%d", i)},
+			directory:  filepath.Join(dir, fmt.Sprintf("repo%d.git", i)),
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	cloneMirrorFn = func(gitCtx context.Context, sshURL, targetDirectory string, auth gitAuth, output io.Writer) error {
+		cancel()
+		return os.MkdirAll(targetDirectory, 0755)
+	}
+
+	results := mirrorAll(ctx, targets, workerSettings(1, time.Second))
+
+	assert.False(t, results[0].skipped)
+	assert.NoError(t, results[0].err)
+	for _, result := range results[1:] {
+		assert.True(t, result.skipped, result.target.repository.FullName)
+		assert.NoError(t, result.err)
+	}
+}
+
+func TestMirrorAll_InFlightCloneFinishesWithinShutdownTimeout(t *testing.T) {
+	dir := t.TempDir()
+	target := mirrorTarget{
+		repository: forge.Repository{ID: 1, FullName: "user/repo1"},
+		directory:  filepath.Join(dir, "repo1.git"),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	cloneMirrorFn = func(gitCtx context.Context, sshURL, targetDirectory string, auth gitAuth, output io.Writer) error {
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		if gitCtx.Err() != nil {
+			return gitCtx.Err()
+		}
+		return os.MkdirAll(targetDirectory, 0755)
+	}
+
+	results := mirrorAll(ctx, []mirrorTarget{target}, workerSettings(1, time.Second))
+
+	assert.False(t, results[0].skipped)
+	assert.NoError(t, results[0].err)
+	assert.DirExists(t, target.directory)
+}
+
+func TestMirrorAll_RollsBackCloneKilledAtShutdownDeadline(t *testing.T) {
+	dir := t.TempDir()
+	target := mirrorTarget{
+		repository: forge.Repository{ID: 1, FullName: "user/repo1"},
+		directory:  filepath.Join(dir, "repo1.git"),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ops := newMockGitOps()
+	setupMocks(t, nil, nil, ops)
+	cloneMirrorFn = func(gitCtx context.Context, sshURL, targetDirectory string, auth gitAuth, output io.Writer) error {
+		os.MkdirAll(filepath.Join(targetDirectory, "objects"), 0755)
+		cancel()
+		<-gitCtx.Done()
+		return errors.New("signal: killed")
+	}
+
+	results := mirrorAll(ctx, []mirrorTarget{target}, workerSettings(1, 10*time.Millisecond))
+
+	assert.True(t, results[0].skipped)
+	assert.NoError(t, results[0].err)
+	assert.NoDirExists(t, target.directory)
+	assert.NoDirExists(t, stagingDirectory(target.directory))
+}
+
+func TestRun_ReportsSkippedRepositoriesOnShutdown(t *testing.T) {
+	dir := t.TempDir()
+	repos := []forge.Repository{
+		{ID: 1, FullName: "user/repo1", SSHURL: "git@github.com:user/repo1.git"},
+		{ID: 2, FullName: "user/repo2", SSHURL: "git@github.com:user/repo2.git"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ops := newMockGitOps()
+	setupMocks(t, repos, nil, ops)
+	cloneMirrorFn = func(gitCtx context.Context, sshURL, targetDirectory string, auth gitAuth, output io.Writer) error {
+		cancel()
+		return os.MkdirAll(targetDirectory, 0755)
+	}
+
+	_, err := run(ctx, dir, testSettings())
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "sync interrupted, 1 repositories skipped")
+	assert.False(t, storedRepository(t, "user/repo1").LastSyncedAt.IsZero())
+	assert.True(t, storedRepository(t, "user/repo2").LastSyncedAt.IsZero())
+}